@@ -0,0 +1,88 @@
+//go:build !race
+// +build !race
+
+// The race detector makes sync.Pool drop items at random, which makes
+// validation allocate.
+
+package jtd_test
+
+import (
+	"runtime/debug"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAllocs(t *testing.T) {
+	// A garbage collection would empty the pool of validation states.
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+
+	for name, bc := range map[string]benchCase{
+		"deep":        deepBench(),
+		"wide":        wideBench(),
+		"array":       arrayBench(),
+		"refs":        refsBench(),
+		"largeSchema": largeSchemaBench(),
+	} {
+		allocs := testing.AllocsPerRun(10, func() {
+			jtd.Validate(bc.schema, bc.instance)
+		})
+
+		assert.Zero(t, allocs, name)
+	}
+}
+
+func TestCompiledValidateAllocs(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+
+	for name, bc := range map[string]benchCase{
+		"deep":  deepBench(),
+		"wide":  wideBench(),
+		"array": arrayBench(),
+		"refs":  refsBench(),
+	} {
+		compiled, err := jtd.Compile(bc.schema)
+		assert.NoError(t, err)
+
+		allocs := testing.AllocsPerRun(10, func() {
+			compiled.Validate(bc.instance)
+		})
+
+		assert.Zero(t, allocs, name)
+	}
+}
+
+func TestCompiledIsValidAllocs(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+
+	for name, bc := range map[string]benchCase{
+		"valid":   arrayBench(),
+		"invalid": invalidBench(),
+	} {
+		compiled, err := jtd.Compile(bc.schema)
+		assert.NoError(t, err)
+
+		allocs := testing.AllocsPerRun(10, func() {
+			compiled.IsValid(bc.instance)
+		})
+
+		assert.Zero(t, allocs, name)
+	}
+}
+
+func TestIsValidAllocs(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+
+	for name, bc := range map[string]benchCase{
+		"valid":       arrayBench(),
+		"invalid":     invalidBench(),
+		"largeSchema": largeSchemaBench(),
+	} {
+		allocs := testing.AllocsPerRun(10, func() {
+			jtd.IsValid(bc.schema, bc.instance)
+		})
+
+		assert.Zero(t, allocs, name)
+	}
+}
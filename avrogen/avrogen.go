@@ -0,0 +1,392 @@
+// Package avrogen exports JSON Typedef schemas as Apache Avro schemas.
+//
+// Schemas are mapped to Avro as follows:
+//
+//	empty             string, containing the value encoded as JSON
+//	boolean           boolean
+//	float32           float
+//	float64           double
+//	int8 ... int32    int
+//	uint8, uint16     int
+//	uint32            long
+//	string            string
+//	timestamp         long, with the timestamp-micros logical type
+//	enum              enum
+//	elements          array
+//	properties        record
+//	values            map
+//	discriminator     union of records, one per mapping value
+//	ref               the named type of the definition, or else the
+//	                  definition's type inlined
+//
+// Nullable schemas become a union of "null" and their type, and optional
+// properties become fields with a default of null. Definitions of the
+// properties and enum forms become named types, defined where they are first
+// used. Other named types are named after the property they are used in. The
+// namespace of all named types is taken from an "avroNamespace" in the
+// metadata of the root schema.
+//
+// Property names and enum values that are not valid Avro names are renamed, and
+// given a numeric suffix if that makes them the same as another name in their
+// record or enum. Each rename is reported as a Loss.
+//
+// Some JSON Typedef schemas cannot be represented exactly in Avro. Generate
+// makes a best effort for these, and reports each one as a Loss.
+package avrogen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// MetadataNamespace is the metadata key of a root schema that sets the
+// namespace of the generated Avro schema.
+const MetadataNamespace = "avroNamespace"
+
+// Loss describes a part of a schema that Avro cannot represent exactly.
+type Loss struct {
+	// Path to the part of the schema that could not be represented exactly,
+	// using the same tokens as the SchemaPath of jtd.ValidateError.
+	SchemaPath []string
+
+	// A human-readable description of what is lost.
+	Message string
+}
+
+// Generate writes an Avro schema for schema to w, as JSON. name is the name of
+// the type generated for the root schema, if it is of the properties or enum
+// form.
+//
+// If schema is of the empty form and has definitions, the Avro schema is
+// instead a union of all the named types made from its definitions.
+func Generate(w io.Writer, schema jtd.Schema, name string) ([]Loss, error) {
+	namespace, _ := schema.Metadata[MetadataNamespace].(string)
+	g := generator{
+		root:      schema,
+		namespace: namespace,
+		defined:   map[string]bool{},
+		names:     map[string]string{},
+		used:      map[string]bool{},
+	}
+
+	for _, name := range schemautil.SortedKeys(schema.Definitions) {
+		switch schema.Definitions[name].Form() {
+		case jtd.FormProperties, jtd.FormEnum:
+			g.names[name] = g.uniqueName(typeName(name))
+		}
+	}
+
+	var t interface{}
+	if schema.Form() == jtd.FormEmpty && len(schema.Definitions) > 0 {
+		// schema is a library of definitions. Output all of the named types in
+		// it, which Avro allows as a union at the top level.
+		var library []interface{}
+		for _, name := range schemautil.SortedKeys(schema.Definitions) {
+			if _, ok := g.names[name]; ok && !g.defined[name] {
+				library = append(library, g.avroType([]string{}, "", jtd.Schema{Ref: &name}))
+			}
+		}
+
+		t = library
+	} else {
+		t = g.avroType([]string{}, typeName(name), schema)
+	}
+
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		return nil, err
+	}
+
+	return g.losses, nil
+}
+
+type generator struct {
+	root      jtd.Schema
+	namespace string
+
+	// names maps the definitions that become named types to their names, and
+	// defined is the set of those that have been defined so far.
+	names   map[string]string
+	defined map[string]bool
+
+	// used is the set of names already taken by named types.
+	used map[string]bool
+
+	// expanding is the set of definitions currently being inlined.
+	expanding []string
+
+	losses []Loss
+}
+
+type record struct {
+	Type      string  `json:"type"`
+	Name      string  `json:"name"`
+	Namespace string  `json:"namespace,omitempty"`
+	Doc       string  `json:"doc,omitempty"`
+	Fields    []field `json:"fields"`
+}
+
+type field struct {
+	Name    string          `json:"name"`
+	Doc     string          `json:"doc,omitempty"`
+	Type    interface{}     `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+type enum struct {
+	Type      string   `json:"type"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace,omitempty"`
+	Doc       string   `json:"doc,omitempty"`
+	Symbols   []string `json:"symbols"`
+}
+
+type array struct {
+	Type  string      `json:"type"`
+	Items interface{} `json:"items"`
+}
+
+type avroMap struct {
+	Type   string      `json:"type"`
+	Values interface{} `json:"values"`
+}
+
+type logical struct {
+	Type        string `json:"type"`
+	LogicalType string `json:"logicalType"`
+}
+
+func (g *generator) lose(path []string, format string, args ...interface{}) {
+	g.losses = append(g.losses, Loss{SchemaPath: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// avroType returns the Avro type for s. name is the name to give s if it
+// becomes a named type.
+func (g *generator) avroType(path []string, name string, s jtd.Schema) interface{} {
+	t := g.nonNullType(path, name, s)
+	if s.Nullable {
+		return nullable(t)
+	}
+
+	return t
+}
+
+func (g *generator) nonNullType(path []string, name string, s jtd.Schema) interface{} {
+	switch s.Form() {
+	case jtd.FormRef:
+		def := g.root.Definitions[*s.Ref]
+		if name, ok := g.names[*s.Ref]; ok {
+			var t interface{} = g.fullName(name)
+			if !g.defined[*s.Ref] {
+				g.defined[*s.Ref] = true
+
+				path := []string{"definitions", *s.Ref}
+				if def.Form() == jtd.FormEnum {
+					t = g.enum(path, name, def)
+				} else {
+					t = g.record(path, name, def)
+				}
+			}
+
+			if def.Nullable {
+				return nullable(t)
+			}
+
+			return t
+		}
+
+		for _, expanding := range g.expanding {
+			if expanding == *s.Ref {
+				g.lose(path, "recursive definition %q is represented as a JSON string", *s.Ref)
+				return "string"
+			}
+		}
+
+		g.expanding = append(g.expanding, *s.Ref)
+		defer func() { g.expanding = g.expanding[:len(g.expanding)-1] }()
+
+		t := g.nonNullType([]string{"definitions", *s.Ref}, name, def)
+		if def.Nullable {
+			return nullable(t)
+		}
+
+		return t
+	case jtd.FormType:
+		switch s.Type {
+		case jtd.TypeBoolean:
+			return "boolean"
+		case jtd.TypeFloat32:
+			return "float"
+		case jtd.TypeFloat64:
+			return "double"
+		case jtd.TypeUint32:
+			return "long"
+		case jtd.TypeString:
+			return "string"
+		case jtd.TypeTimestamp:
+			g.lose(path, "timestamps lose their UTC offset and sub-microsecond precision")
+			return logical{Type: "long", LogicalType: "timestamp-micros"}
+		default:
+			return "int"
+		}
+	case jtd.FormEnum:
+		return g.enum(path, g.uniqueName(name), s)
+	case jtd.FormElements:
+		return array{Type: "array", Items: g.avroType(schemautil.AppendPath(path, "elements"), name+"Item", *s.Elements)}
+	case jtd.FormProperties:
+		return g.record(path, g.uniqueName(name), s)
+	case jtd.FormValues:
+		return avroMap{Type: "map", Values: g.avroType(schemautil.AppendPath(path, "values"), name+"Value", *s.Values)}
+	case jtd.FormDiscriminator:
+		g.lose(schemautil.AppendPath(path, "discriminator"), "the %q property is represented by which branch of the union is used", s.Discriminator)
+
+		var union []interface{}
+		for _, tag := range schemautil.SortedKeys(s.Mapping) {
+			union = append(union, g.record(schemautil.AppendPath(path, "mapping", tag), g.uniqueName(name+typeName(tag)), s.Mapping[tag]))
+		}
+
+		return union
+	default:
+		g.lose(path, "arbitrary values are represented as JSON strings")
+		return "string"
+	}
+}
+
+func (g *generator) enum(path []string, name string, s jtd.Schema) enum {
+	e := enum{Type: "enum", Name: name, Namespace: g.namespace, Doc: s.Description()}
+	used := map[string]bool{}
+	for _, value := range s.Enum {
+		symbol := uniqueName(used, avroName(value))
+		if symbol != value {
+			g.lose(schemautil.AppendPath(path, "enum"), "enum value %q is renamed to %s", value, symbol)
+		}
+
+		e.Symbols = append(e.Symbols, symbol)
+	}
+
+	return e
+}
+
+func (g *generator) record(path []string, name string, s jtd.Schema) record {
+	r := record{Type: "record", Name: name, Namespace: g.namespace, Doc: s.Description(), Fields: []field{}}
+	used := map[string]bool{}
+
+	for _, required := range []bool{true, false} {
+		keyword, properties := "properties", s.Properties
+		if !required {
+			keyword, properties = "optionalProperties", s.OptionalProperties
+		}
+
+		for _, key := range schemautil.SortedKeys(properties) {
+			path := schemautil.AppendPath(path, keyword, key)
+			f := field{Name: uniqueName(used, avroName(key)), Doc: properties[key].Description()}
+			if f.Name != key {
+				g.lose(path, "property %q is renamed to %s", key, f.Name)
+			}
+
+			f.Type = g.avroType(path, name+typeName(key), properties[key])
+			if !required {
+				if _, ok := f.Type.(union); ok {
+					g.lose(path, "absent is not distinguished from null")
+				} else {
+					f.Type = nullable(f.Type)
+				}
+
+				f.Default = json.RawMessage("null")
+			}
+
+			r.Fields = append(r.Fields, f)
+		}
+	}
+
+	if s.AdditionalProperties {
+		g.lose(schemautil.AppendPath(path, "additionalProperties"), "additional properties are dropped")
+	}
+
+	return r
+}
+
+// union is the type of a nullable type, which in Avro is a union with "null".
+type union []interface{}
+
+// nullable returns the union of "null" and t.
+func nullable(t interface{}) interface{} {
+	switch t := t.(type) {
+	case union:
+		return t
+	case []interface{}:
+		// t is itself a union, from a discriminator. Avro does not allow
+		// nested unions, so add "null" to t instead.
+		return union(append([]interface{}{"null"}, t...))
+	default:
+		return union{"null", t}
+	}
+}
+
+func (g *generator) uniqueName(name string) string {
+	return uniqueName(g.used, name)
+}
+
+// uniqueName returns name, or if it is in used, name followed by the smallest
+// number that makes it unique. The result is added to used.
+func uniqueName(used map[string]bool, name string) string {
+	out := name
+	for i := 2; used[out]; i++ {
+		out = fmt.Sprintf("%s%d", name, i)
+	}
+
+	used[out] = true
+	return out
+}
+
+func (g *generator) fullName(name string) string {
+	if g.namespace == "" {
+		return name
+	}
+
+	return g.namespace + "." + name
+}
+
+var nonName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// avroName returns s, with characters not allowed in Avro names replaced.
+func avroName(s string) string {
+	s = nonName.ReplaceAllString(s, "_")
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "_" + s
+	}
+
+	return s
+}
+
+// typeName returns s in PascalCase, for use as the name of a named type.
+func typeName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	return avroName(b.String())
+}
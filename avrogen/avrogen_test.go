@@ -0,0 +1,189 @@
+package avrogen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/avrogen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"metadata": { "avroNamespace": "com.acme" },
+		"definitions": {
+			"status": { "enum": ["ACTIVE", "on-hold"] },
+			"event": {
+				"nullable": true,
+				"discriminator": "kind",
+				"mapping": {
+					"created": { "properties": { "at": { "type": "timestamp" } } },
+					"deleted": { "properties": {} }
+				}
+			}
+		},
+		"properties": {
+			"count": { "type": "uint32" },
+			"status": { "ref": "status" },
+			"previous": { "ref": "status", "nullable": true },
+			"event": { "ref": "event" },
+			"tags": { "values": { "elements": { "type": "boolean" } } },
+			"extra": {}
+		},
+		"optionalProperties": {
+			"line-note": { "type": "string", "nullable": true }
+		}
+	}`), &schema))
+	assert.NoError(t, schema.Validate())
+
+	var b bytes.Buffer
+	losses, err := avrogen.Generate(&b, schema, "order")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "record",
+		"name": "Order",
+		"namespace": "com.acme",
+		"fields": [
+			{ "name": "count", "type": "long" },
+			{
+				"name": "event",
+				"type": [
+					"null",
+					{
+						"type": "record",
+						"name": "OrderEventCreated",
+						"namespace": "com.acme",
+						"fields": [
+							{ "name": "at", "type": { "type": "long", "logicalType": "timestamp-micros" } }
+						]
+					},
+					{
+						"type": "record",
+						"name": "OrderEventDeleted",
+						"namespace": "com.acme",
+						"fields": []
+					}
+				]
+			},
+			{ "name": "extra", "type": "string" },
+			{
+				"name": "previous",
+				"type": [
+					"null",
+					{
+						"type": "enum",
+						"name": "Status",
+						"namespace": "com.acme",
+						"symbols": ["ACTIVE", "on_hold"]
+					}
+				]
+			},
+			{ "name": "status", "type": "com.acme.Status" },
+			{
+				"name": "tags",
+				"type": { "type": "map", "values": { "type": "array", "items": "boolean" } }
+			},
+			{ "name": "line_note", "type": ["null", "string"], "default": null }
+		]
+	}`, b.String())
+
+	assert.Equal(t, []avrogen.Loss{
+		{SchemaPath: []string{"definitions", "event", "discriminator"}, Message: "the \"kind\" property is represented by which branch of the union is used"},
+		{SchemaPath: []string{"definitions", "event", "mapping", "created", "properties", "at"}, Message: "timestamps lose their UTC offset and sub-microsecond precision"},
+		{SchemaPath: []string{"properties", "extra"}, Message: "arbitrary values are represented as JSON strings"},
+		{SchemaPath: []string{"definitions", "status", "enum"}, Message: "enum value \"on-hold\" is renamed to on_hold"},
+		{SchemaPath: []string{"optionalProperties", "line-note"}, Message: "property \"line-note\" is renamed to line_note"},
+		{SchemaPath: []string{"optionalProperties", "line-note"}, Message: "absent is not distinguished from null"},
+	}, losses)
+}
+
+func TestGenerateLibrary(t *testing.T) {
+	node := "node"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"node": jtd.Schema{
+				Properties: map[string]jtd.Schema{
+					"children": jtd.Schema{Elements: &jtd.Schema{Ref: &node}},
+				},
+			},
+			"color": jtd.Schema{Enum: []string{"RED"}},
+			"name":  jtd.Schema{Type: jtd.TypeString},
+		},
+	}
+
+	var b bytes.Buffer
+	losses, err := avrogen.Generate(&b, schema, "root")
+	assert.NoError(t, err)
+	assert.Empty(t, losses)
+	assert.JSONEq(t, `[
+		{ "type": "enum", "name": "Color", "symbols": ["RED"] },
+		{
+			"type": "record",
+			"name": "Node",
+			"fields": [
+				{ "name": "children", "type": { "type": "array", "items": "Node" } }
+			]
+		}
+	]`, b.String())
+}
+
+func TestGenerateNameCollisions(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"properties": {
+			"a-b": { "type": "string" },
+			"a_b": { "enum": ["x-y", "x_y"] }
+		},
+		"optionalProperties": {
+			"a.b": { "type": "string" }
+		}
+	}`), &schema))
+
+	var b bytes.Buffer
+	losses, err := avrogen.Generate(&b, schema, "root")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "record",
+		"name": "Root",
+		"fields": [
+			{ "name": "a_b", "type": "string" },
+			{
+				"name": "a_b2",
+				"type": { "type": "enum", "name": "RootAB", "symbols": ["x_y", "x_y2"] }
+			},
+			{ "name": "a_b3", "type": ["null", "string"], "default": null }
+		]
+	}`, b.String())
+	assert.Equal(t, []avrogen.Loss{
+		{SchemaPath: []string{"properties", "a-b"}, Message: "property \"a-b\" is renamed to a_b"},
+		{SchemaPath: []string{"properties", "a_b"}, Message: "property \"a_b\" is renamed to a_b2"},
+		{SchemaPath: []string{"properties", "a_b", "enum"}, Message: "enum value \"x-y\" is renamed to x_y"},
+		{SchemaPath: []string{"properties", "a_b", "enum"}, Message: "enum value \"x_y\" is renamed to x_y2"},
+		{SchemaPath: []string{"optionalProperties", "a.b"}, Message: "property \"a.b\" is renamed to a_b3"},
+	}, losses)
+}
+
+func ExampleGenerate() {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"name": jtd.Schema{Type: jtd.TypeString},
+		},
+	}
+
+	avrogen.Generate(os.Stdout, schema, "user")
+	// Output:
+	// {
+	//   "type": "record",
+	//   "name": "User",
+	//   "fields": [
+	//     {
+	//       "name": "name",
+	//       "type": "string"
+	//     }
+	//   ]
+	// }
+}
@@ -0,0 +1,278 @@
+package jtd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// RecordErrors are the validation errors of one record of an NDJSON stream.
+type RecordErrors struct {
+	// Record is the zero-based index of the record in the stream. Blank lines
+	// are not records.
+	Record int
+
+	// Errors are the errors Validate returned for the record.
+	Errors []ValidateError
+}
+
+// ValidateBatch validates each of instances against schema, using the given
+// number of goroutines. If workers is zero or less, runtime.GOMAXPROCS(0) are
+// used.
+//
+// ValidateBatch is equivalent to ValidateBatchContext with a background
+// context.
+func ValidateBatch(schema Schema, instances []interface{}, workers int, opts ...ValidateOption) ([][]ValidateError, error) {
+	return ValidateBatchContext(context.Background(), schema, instances, workers, opts...)
+}
+
+// ValidateBatchContext validates each of instances against schema, using the
+// given number of goroutines. If workers is zero or less,
+// runtime.GOMAXPROCS(0) are used.
+//
+// The returned slice has the errors of instances[i] at index i. MaxErrors, if
+// set, limits the total number of errors across all instances: once it is
+// reached, all goroutines stop, and instances that were not validated have nil
+// errors, whereas valid instances have an empty, non-nil list. Which instances
+// get validated before the limit is reached depends on scheduling.
+//
+// If ctx is done before every instance is validated, ValidateBatchContext
+// returns ctx.Err(). If validating any instance returns an error, such as ErrMaxDepthExceeded,
+// ValidateBatchContext stops and returns that error.
+func ValidateBatchContext(ctx context.Context, schema Schema, instances []interface{}, workers int, opts ...ValidateOption) ([][]ValidateError, error) {
+	out := make([][]ValidateError, len(instances))
+	b := newBatchValidator(ctx, schema, opts)
+
+	jobs := make(chan int)
+	b.start(workers, func() {
+		for i := range jobs {
+			if errs, ok := b.validate(instances[i]); ok {
+				out[i] = errs
+			}
+		}
+	})
+
+send:
+	for i := range instances {
+		select {
+		case jobs <- i:
+		case <-b.ctx.Done():
+			b.skip()
+			break send
+		}
+	}
+
+	close(jobs)
+	if err := b.wait(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ValidateNDJSON validates each record of the newline-delimited JSON stream r
+// against schema, using the given number of goroutines to parse and validate
+// records. If workers is zero or less, runtime.GOMAXPROCS(0) are used.
+//
+// ValidateNDJSON returns the errors of the records that are not valid, ordered
+// by record. Lines are read one at a time, so r may be arbitrarily large.
+//
+// MaxErrors, ctx, and errors from Validate are treated as in
+// ValidateBatchContext. ValidateNDJSON also returns an error, and stops, if a
+// record is not valid JSON or if reading r fails.
+func ValidateNDJSON(ctx context.Context, schema Schema, r io.Reader, workers int, opts ...ValidateOption) ([]RecordErrors, error) {
+	type job struct {
+		record int
+		data   []byte
+	}
+
+	var mu sync.Mutex
+	out := []RecordErrors{}
+	b := newBatchValidator(ctx, schema, opts)
+
+	jobs := make(chan job)
+	b.start(workers, func() {
+		for j := range jobs {
+			var instance interface{}
+			if err := json.Unmarshal(j.data, &instance); err != nil {
+				b.fail(fmt.Errorf("jtd: record %d: %w", j.record, err))
+				continue
+			}
+
+			if errs, ok := b.validate(instance); ok && len(errs) > 0 {
+				mu.Lock()
+				out = append(out, RecordErrors{Record: j.record, Errors: errs})
+				mu.Unlock()
+			}
+		}
+	})
+
+	reader := bufio.NewReader(r)
+read:
+	for record := 0; ; {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			select {
+			case jobs <- job{record: record, data: line}:
+			case <-b.ctx.Done():
+				b.skip()
+				break read
+			}
+
+			record++
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			b.fail(err)
+			break
+		}
+	}
+
+	close(jobs)
+	if err := b.wait(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Record < out[j].Record
+	})
+
+	return out, nil
+}
+
+// batchValidator coordinates the goroutines of a batch validation.
+type batchValidator struct {
+	parent   context.Context
+	ctx      context.Context
+	cancel   context.CancelFunc
+	schema   *CompiledSchema
+	settings ValidateSettings
+	wg       sync.WaitGroup
+
+	// mu guards the fields below it.
+	mu        sync.Mutex
+	remaining int
+	err       error
+
+	// skipped is whether any instance was left unvalidated because b was
+	// stopped.
+	skipped bool
+}
+
+func newBatchValidator(parent context.Context, schema Schema, opts []ValidateOption) *batchValidator {
+	settings := applyValidateOptions(opts)
+
+	ctx, cancel := context.WithCancel(parent)
+	return &batchValidator{
+		parent:    parent,
+		ctx:       ctx,
+		cancel:    cancel,
+		schema:    compile(schema),
+		settings:  settings,
+		remaining: settings.MaxErrors,
+	}
+}
+
+func (b *batchValidator) start(workers int, worker func()) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	b.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer b.wg.Done()
+			worker()
+		}()
+	}
+}
+
+// validate validates instance, counting its errors against MaxErrors. It
+// returns false if b was stopped before instance could be validated.
+func (b *batchValidator) validate(instance interface{}) ([]ValidateError, bool) {
+	settings := b.settings
+
+	b.mu.Lock()
+	stopped := b.ctx.Err() != nil
+	settings.MaxErrors = b.remaining
+	b.skipped = b.skipped || stopped
+	b.mu.Unlock()
+
+	if stopped {
+		return nil, false
+	}
+
+	errs, err := b.schema.ValidateWithSettings(settings, instance)
+	if err != nil {
+		b.fail(err)
+		return nil, false
+	}
+
+	if b.settings.MaxErrors == 0 || len(errs) == 0 {
+		return errs, true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.remaining == 0 {
+		return nil, false
+	}
+
+	if len(errs) >= b.remaining {
+		errs = errs[:b.remaining]
+		b.cancel()
+	}
+
+	b.remaining -= len(errs)
+	return errs, true
+}
+
+// fail stops b, recording err if it is the first error.
+func (b *batchValidator) fail(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err == nil {
+		b.err = err
+	}
+
+	b.cancel()
+}
+
+// skip records that an instance was left unvalidated because b was stopped.
+func (b *batchValidator) skip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.skipped = true
+}
+
+// wait waits for the workers to finish, and returns the error b stopped with,
+// if any. If the parent context is done, its error is returned only if some
+// instances were skipped because of it, so that a batch that was fully
+// validated is never discarded.
+func (b *batchValidator) wait() error {
+	b.wg.Wait()
+	b.cancel()
+
+	if b.err != nil {
+		return b.err
+	}
+
+	if b.skipped {
+		return b.parent.Err()
+	}
+
+	return nil
+}
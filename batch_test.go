@@ -0,0 +1,151 @@
+package jtd_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var batchSchema = jtd.Schema{
+	Properties: map[string]jtd.Schema{
+		"id":   jtd.Schema{Type: jtd.TypeUint32},
+		"name": jtd.Schema{Type: jtd.TypeString},
+	},
+}
+
+func batchInstances(n int) []interface{} {
+	instances := make([]interface{}, n)
+	for i := range instances {
+		if i%3 == 0 {
+			instances[i] = map[string]interface{}{"id": -1.0, "name": 1.0}
+		} else {
+			instances[i] = map[string]interface{}{"id": float64(i), "name": "x"}
+		}
+	}
+
+	return instances
+}
+
+func TestValidateBatch(t *testing.T) {
+	instances := batchInstances(100)
+
+	for _, workers := range []int{0, 1, 4} {
+		out, err := jtd.ValidateBatch(batchSchema, instances, workers)
+		assert.NoError(t, err)
+		assert.Len(t, out, len(instances))
+
+		for i, instance := range instances {
+			errs, err := jtd.Validate(batchSchema, instance)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, errs, out[i], "instance %d", i)
+			assert.NotNil(t, out[i], "instance %d", i)
+		}
+	}
+}
+
+func TestValidateBatchMaxErrors(t *testing.T) {
+	instances := batchInstances(1000)
+
+	out, err := jtd.ValidateBatch(batchSchema, instances, 4, jtd.WithMaxErrors(5))
+	assert.NoError(t, err)
+
+	total := 0
+	for _, errs := range out {
+		total += len(errs)
+	}
+
+	assert.Equal(t, 5, total)
+
+	// Not all instances were validated.
+	assert.Contains(t, out, []jtd.ValidateError(nil))
+}
+
+func TestValidateBatchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := jtd.ValidateBatchContext(ctx, batchSchema, batchInstances(1000), 4)
+	assert.Equal(t, context.Canceled, err)
+
+	// Nothing was skipped, so there's no error.
+	out, err := jtd.ValidateBatchContext(ctx, batchSchema, nil, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+
+	records, err := jtd.ValidateNDJSON(ctx, batchSchema, strings.NewReader("\n\n"), 4)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestValidateBatchError(t *testing.T) {
+	loop := "loop"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{"loop": jtd.Schema{Ref: &loop}},
+		Ref:         &loop,
+	}
+
+	_, err := jtd.ValidateBatch(schema, batchInstances(10), 2)
+	assert.Equal(t, jtd.ErrUnproductiveRefCycle, err)
+}
+
+func TestValidateNDJSON(t *testing.T) {
+	var lines []string
+	for _, instance := range batchInstances(100) {
+		b, err := json.Marshal(instance)
+		assert.NoError(t, err)
+		lines = append(lines, string(b), "")
+	}
+
+	out, err := jtd.ValidateNDJSON(context.Background(), batchSchema, strings.NewReader(strings.Join(lines, "\n")), 4)
+	assert.NoError(t, err)
+	assert.Len(t, out, 34)
+
+	for i, record := range out {
+		assert.Equal(t, i*3, record.Record)
+		assert.Len(t, record.Errors, 2)
+	}
+
+	out, err = jtd.ValidateNDJSON(context.Background(), batchSchema, strings.NewReader(strings.Join(lines, "\n")), 4, jtd.WithMaxErrors(3))
+	assert.NoError(t, err)
+
+	total := 0
+	for _, record := range out {
+		total += len(record.Errors)
+	}
+
+	assert.Equal(t, 3, total)
+}
+
+func TestValidateNDJSONSyntaxError(t *testing.T) {
+	r := strings.NewReader("{\"id\": 1, \"name\": \"x\"}\n{\"id\": \n")
+
+	_, err := jtd.ValidateNDJSON(context.Background(), batchSchema, r, 2)
+
+	var syntaxErr *json.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr), "unexpected error: %v", err)
+	assert.Contains(t, err.Error(), "record 1")
+}
+
+func ExampleValidateNDJSON() {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"name": jtd.Schema{Type: jtd.TypeString},
+		},
+	}
+
+	r := strings.NewReader(`{"name": "a"}
+{"name": 1}
+{"name": "c"}
+`)
+
+	out, err := jtd.ValidateNDJSON(context.Background(), schema, r, 2)
+	fmt.Println(out, err)
+	// Output:
+	// [{1 [{[name] [properties name type]}]}] <nil>
+}
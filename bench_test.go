@@ -0,0 +1,321 @@
+package jtd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+)
+
+// The benchmarks in this file validate instances that are valid, unless noted
+// otherwise, so that they measure the cost of validation rather than of
+// building up errors.
+
+type benchCase struct {
+	schema   jtd.Schema
+	instance interface{}
+}
+
+// deepBench is a tree of nested objects, 64 levels deep.
+func deepBench() benchCase {
+	var schema jtd.Schema
+	mustUnmarshal(`{
+		"definitions": {
+			"node": {
+				"properties": { "name": { "type": "string" } },
+				"optionalProperties": { "child": { "ref": "node" } }
+			}
+		},
+		"ref": "node"
+	}`, &schema)
+
+	instance := map[string]interface{}{"name": "leaf"}
+	for i := 0; i < 64; i++ {
+		instance = map[string]interface{}{"name": "node", "child": instance}
+	}
+
+	return benchCase{schema, instance}
+}
+
+// wideBench is an object with 256 properties of various types.
+func wideBench() benchCase {
+	schema := jtd.Schema{Properties: map[string]jtd.Schema{}}
+	instance := map[string]interface{}{}
+	for i := 0; i < 256; i++ {
+		name := fmt.Sprintf("property%d", i)
+		switch i % 4 {
+		case 0:
+			schema.Properties[name] = jtd.Schema{Type: jtd.TypeString}
+			instance[name] = "value"
+		case 1:
+			schema.Properties[name] = jtd.Schema{Type: jtd.TypeUint32}
+			instance[name] = float64(i)
+		case 2:
+			schema.Properties[name] = jtd.Schema{Enum: []string{"A", "B", "C"}}
+			instance[name] = "C"
+		case 3:
+			schema.Properties[name] = jtd.Schema{Type: jtd.TypeBoolean, Nullable: true}
+			instance[name] = nil
+		}
+	}
+
+	return benchCase{schema, instance}
+}
+
+// arrayBench is an array of 10,000 small records.
+func arrayBench() benchCase {
+	var schema jtd.Schema
+	mustUnmarshal(`{
+		"elements": {
+			"properties": {
+				"id": { "type": "uint32" },
+				"createdAt": { "type": "timestamp" },
+				"tags": { "values": { "type": "string" } }
+			}
+		}
+	}`, &schema)
+
+	instance := make([]interface{}, 10000)
+	for i := range instance {
+		instance[i] = map[string]interface{}{
+			"id":        float64(i),
+			"createdAt": "2020-01-01T00:00:00Z",
+			"tags":      map[string]interface{}{"a": "b"},
+		}
+	}
+
+	return benchCase{schema, instance}
+}
+
+// refsBench follows a chain of 100 definitions, and discriminators within them.
+func refsBench() benchCase {
+	schema := jtd.Schema{Definitions: map[string]jtd.Schema{}}
+	for i := 0; i < 100; i++ {
+		next := fmt.Sprintf("def%d", i+1)
+		props := map[string]jtd.Schema{"value": jtd.Schema{Type: jtd.TypeFloat64}}
+		if i < 99 {
+			props["next"] = jtd.Schema{Ref: &next}
+		}
+
+		schema.Definitions[fmt.Sprintf("def%d", i)] = jtd.Schema{
+			Discriminator: "kind",
+			Mapping: map[string]jtd.Schema{
+				"a": jtd.Schema{Properties: props},
+				"b": jtd.Schema{Properties: map[string]jtd.Schema{}},
+			},
+		}
+	}
+
+	first := "def0"
+	schema.Ref = &first
+
+	instance := map[string]interface{}{"kind": "a", "value": 99.0}
+	for i := 0; i < 99; i++ {
+		instance = map[string]interface{}{"kind": "a", "value": float64(i), "next": instance}
+	}
+
+	return benchCase{schema, instance}
+}
+
+// largeSchemaBench is a small instance of a schema with 300 definitions of 10
+// optional properties each, most of which the instance never gets to.
+func largeSchemaBench() benchCase {
+	schema := jtd.Schema{Definitions: map[string]jtd.Schema{}}
+	for i := 0; i < 300; i++ {
+		next := fmt.Sprintf("def%d", (i+1)%300)
+		props := map[string]jtd.Schema{"next": jtd.Schema{Ref: &next}}
+		for j := 0; j < 9; j++ {
+			props[fmt.Sprintf("property%d", j)] = jtd.Schema{Type: jtd.TypeString}
+		}
+
+		schema.Definitions[fmt.Sprintf("def%d", i)] = jtd.Schema{OptionalProperties: props}
+	}
+
+	first := "def0"
+	schema.Ref = &first
+
+	instance := map[string]interface{}{
+		"property0": "a",
+		"next":      map[string]interface{}{"property1": "b"},
+	}
+
+	return benchCase{schema, instance}
+}
+
+// invalidBench is an array of 1,000 records that each have two errors.
+func invalidBench() benchCase {
+	bc := arrayBench()
+	instance := make([]interface{}, 1000)
+	for i := range instance {
+		instance[i] = map[string]interface{}{
+			"id":        -1.0,
+			"createdAt": "2020-01-01T00:00:00Z",
+			"tags":      map[string]interface{}{"a": 1.0},
+		}
+	}
+
+	return benchCase{bc.schema, instance}
+}
+
+func mustUnmarshal(data string, v interface{}) {
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		panic(err)
+	}
+}
+
+func benchmarkValidate(b *testing.B, bc benchCase) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := jtd.Validate(bc.schema, bc.instance); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkCompiledValidate(b *testing.B, bc benchCase) {
+	compiled, err := jtd.Compile(bc.schema)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := compiled.Validate(bc.instance); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateDeep(b *testing.B)         { benchmarkValidate(b, deepBench()) }
+func BenchmarkValidateWide(b *testing.B)         { benchmarkValidate(b, wideBench()) }
+func BenchmarkValidateArray(b *testing.B)        { benchmarkValidate(b, arrayBench()) }
+func BenchmarkValidateRefs(b *testing.B)         { benchmarkValidate(b, refsBench()) }
+func BenchmarkValidateInvalid(b *testing.B)      { benchmarkValidate(b, invalidBench()) }
+func BenchmarkValidateLargeSchema(b *testing.B)  { benchmarkValidate(b, largeSchemaBench()) }
+func BenchmarkCompiledValidateDeep(b *testing.B) { benchmarkCompiledValidate(b, deepBench()) }
+func BenchmarkCompiledValidateWide(b *testing.B) { benchmarkCompiledValidate(b, wideBench()) }
+func BenchmarkCompiledValidateArray(b *testing.B) {
+	benchmarkCompiledValidate(b, arrayBench())
+}
+func BenchmarkCompiledValidateRefs(b *testing.B) { benchmarkCompiledValidate(b, refsBench()) }
+func BenchmarkCompiledValidateInvalid(b *testing.B) {
+	benchmarkCompiledValidate(b, invalidBench())
+}
+func BenchmarkCompiledValidateLargeSchema(b *testing.B) {
+	benchmarkCompiledValidate(b, largeSchemaBench())
+}
+
+func BenchmarkIsValidInvalid(b *testing.B) {
+	bc := invalidBench()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := jtd.IsValid(bc.schema, bc.instance); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledIsValidInvalid(b *testing.B) {
+	bc := invalidBench()
+	compiled, err := jtd.Compile(bc.schema)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := compiled.IsValid(bc.instance); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSchemaValidate(b *testing.B) {
+	for _, bc := range []benchCase{deepBench(), wideBench(), arrayBench(), refsBench()} {
+		if err := bc.schema.Validate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	schemas := []jtd.Schema{deepBench().schema, wideBench().schema, arrayBench().schema, refsBench().schema}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, schema := range schemas {
+			schema.Validate()
+		}
+	}
+}
+
+// loadSpecCases returns the schemas and instances of the spec's validation test
+// suite, skipping b if the suite is not checked out.
+func loadSpecCases(b *testing.B) []testCase {
+	spec, err := ioutil.ReadFile("json-typedef-spec/tests/validation.json")
+	if err != nil {
+		b.Skip("spec test suite not available:", err)
+	}
+
+	var testCases map[string]testCase
+	if err := json.Unmarshal(spec, &testCases); err != nil {
+		b.Fatal(err)
+	}
+
+	out := make([]testCase, 0, len(testCases))
+	for _, tt := range testCases {
+		out = append(out, tt)
+	}
+
+	return out
+}
+
+func BenchmarkSchemaValidateSpec(b *testing.B) {
+	testCases := loadSpecCases(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, tt := range testCases {
+			tt.Schema.Validate()
+		}
+	}
+}
+
+func BenchmarkValidateSpec(b *testing.B) {
+	testCases := loadSpecCases(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, tt := range testCases {
+			jtd.Validate(tt.Schema, tt.Instance)
+		}
+	}
+}
+
+func BenchmarkCompiledValidateSpec(b *testing.B) {
+	testCases := loadSpecCases(b)
+
+	compiled := make([]*jtd.CompiledSchema, len(testCases))
+	for i, tt := range testCases {
+		c, err := jtd.Compile(tt.Schema)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		compiled[i] = c
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, tt := range testCases {
+			compiled[j].Validate(tt.Instance)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package jtd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The functions in this file build schemas out of Go code. Each constructor
+// produces a schema of exactly one form, so unlike a Schema literal, a builder
+// cannot mix up keywords of different forms or use an invalid type:
+//
+//	schema, err := jtd.Build(jtd.Properties(
+//		jtd.Required("id", jtd.String()),
+//		jtd.Optional("tags", jtd.Elements(jtd.Ref("tag"))),
+//	), map[string]jtd.SchemaBuilder{
+//		"tag": jtd.Enum("RED", "GREEN"),
+//	})
+//
+// Builders are immutable; their methods return modified copies.
+
+// ErrRepeatedProperty indicates that a builder for the properties form was
+// given the same property more than once.
+var ErrRepeatedProperty = errors.New("jtd: property specified more than once")
+
+// SchemaBuilder is implemented by Builder and PropertiesBuilder. It cannot be
+// implemented outside of this package.
+type SchemaBuilder interface {
+	build() (Schema, error)
+}
+
+// Builder builds a schema of any form.
+type Builder struct {
+	schema Schema
+	err    error
+}
+
+// Nullable returns a copy of b that also accepts null.
+func (b Builder) Nullable() Builder {
+	b.schema.Nullable = true
+	return b
+}
+
+// WithMetadata returns a copy of b with the metadata key set to value.
+func (b Builder) WithMetadata(key string, value interface{}) Builder {
+	b.schema.Metadata = withMetadata(b.schema.Metadata, key, value)
+	return b
+}
+
+func (b Builder) build() (Schema, error) {
+	return b.schema, b.err
+}
+
+// PropertiesBuilder builds a schema of the properties form. Unlike Builder, it
+// can be used as a value of a discriminator mapping.
+type PropertiesBuilder struct {
+	schema Schema
+	err    error
+}
+
+// AdditionalProperties returns a copy of b that accepts properties other than
+// the ones it was built with.
+func (b PropertiesBuilder) AdditionalProperties() PropertiesBuilder {
+	b.schema.AdditionalProperties = true
+	return b
+}
+
+// Nullable returns a Builder for a copy of b that also accepts null. Nullable
+// schemas cannot be used as the values of a discriminator mapping.
+func (b PropertiesBuilder) Nullable() Builder {
+	b.schema.Nullable = true
+	return Builder{schema: b.schema, err: b.err}
+}
+
+// WithMetadata returns a copy of b with the metadata key set to value.
+func (b PropertiesBuilder) WithMetadata(key string, value interface{}) PropertiesBuilder {
+	b.schema.Metadata = withMetadata(b.schema.Metadata, key, value)
+	return b
+}
+
+func (b PropertiesBuilder) build() (Schema, error) {
+	return b.schema, b.err
+}
+
+// Property is a property of a PropertiesBuilder, created with Required or
+// Optional.
+type Property struct {
+	name     string
+	optional bool
+	schema   SchemaBuilder
+}
+
+// Required returns a required property with the given name and schema.
+func Required(name string, schema SchemaBuilder) Property {
+	return Property{name: name, schema: schema}
+}
+
+// Optional returns an optional property with the given name and schema.
+func Optional(name string, schema SchemaBuilder) Property {
+	return Property{name: name, optional: true, schema: schema}
+}
+
+// Empty returns a builder for the empty form, which accepts any value.
+func Empty() Builder {
+	return Builder{}
+}
+
+// Ref returns a builder for the ref form, referring to the given definition.
+func Ref(definition string) Builder {
+	return Builder{schema: Schema{Ref: &definition}}
+}
+
+// Boolean returns a builder for the type form, with type TypeBoolean.
+func Boolean() Builder { return typeBuilder(TypeBoolean) }
+
+// String returns a builder for the type form, with type TypeString.
+func String() Builder { return typeBuilder(TypeString) }
+
+// Timestamp returns a builder for the type form, with type TypeTimestamp.
+func Timestamp() Builder { return typeBuilder(TypeTimestamp) }
+
+// Float32 returns a builder for the type form, with type TypeFloat32.
+func Float32() Builder { return typeBuilder(TypeFloat32) }
+
+// Float64 returns a builder for the type form, with type TypeFloat64.
+func Float64() Builder { return typeBuilder(TypeFloat64) }
+
+// Int8 returns a builder for the type form, with type TypeInt8.
+func Int8() Builder { return typeBuilder(TypeInt8) }
+
+// Uint8 returns a builder for the type form, with type TypeUint8.
+func Uint8() Builder { return typeBuilder(TypeUint8) }
+
+// Int16 returns a builder for the type form, with type TypeInt16.
+func Int16() Builder { return typeBuilder(TypeInt16) }
+
+// Uint16 returns a builder for the type form, with type TypeUint16.
+func Uint16() Builder { return typeBuilder(TypeUint16) }
+
+// Int32 returns a builder for the type form, with type TypeInt32.
+func Int32() Builder { return typeBuilder(TypeInt32) }
+
+// Uint32 returns a builder for the type form, with type TypeUint32.
+func Uint32() Builder { return typeBuilder(TypeUint32) }
+
+func typeBuilder(t Type) Builder {
+	return Builder{schema: Schema{Type: t}}
+}
+
+// Enum returns a builder for the enum form, accepting the given values.
+func Enum(values ...string) Builder {
+	enum := make([]string, len(values))
+	copy(enum, values)
+
+	return Builder{schema: Schema{Enum: enum}}
+}
+
+// Elements returns a builder for the elements form, whose elements match
+// schema.
+func Elements(schema SchemaBuilder) Builder {
+	s, err := schema.build()
+	return Builder{schema: Schema{Elements: &s}, err: err}
+}
+
+// Values returns a builder for the values form, whose values match schema.
+func Values(schema SchemaBuilder) Builder {
+	s, err := schema.build()
+	return Builder{schema: Schema{Values: &s}, err: err}
+}
+
+// Properties returns a builder for the properties form, with the given
+// properties. The properties keyword is always present in the built schema,
+// even if there are no required properties.
+func Properties(properties ...Property) PropertiesBuilder {
+	b := PropertiesBuilder{schema: Schema{Properties: map[string]Schema{}}}
+	for _, p := range properties {
+		s, err := p.schema.build()
+		if err != nil && b.err == nil {
+			b.err = err
+		}
+
+		target := b.schema.Properties
+		if p.optional {
+			if b.schema.OptionalProperties == nil {
+				b.schema.OptionalProperties = map[string]Schema{}
+			}
+
+			target = b.schema.OptionalProperties
+		}
+
+		if _, ok := target[p.name]; ok && b.err == nil {
+			b.err = fmt.Errorf("%w: %q", ErrRepeatedProperty, p.name)
+		}
+
+		target[p.name] = s
+	}
+
+	return b
+}
+
+// Discriminator returns a builder for the discriminator form, with the given
+// tag and mapping.
+func Discriminator(tag string, mapping map[string]PropertiesBuilder) Builder {
+	b := Builder{schema: Schema{Discriminator: tag, Mapping: make(map[string]Schema, len(mapping))}}
+	for name, value := range mapping {
+		s, err := value.build()
+		if err != nil && b.err == nil {
+			b.err = err
+		}
+
+		b.schema.Mapping[name] = s
+	}
+
+	return b
+}
+
+// Build returns a root schema built out of root and definitions.
+//
+// Build returns an error if the schema would be invalid according to
+// Schema.Validate, such as when a ref refers to a definition that does not
+// exist, or if a property was specified more than once.
+func Build(root SchemaBuilder, definitions map[string]SchemaBuilder) (Schema, error) {
+	schema, err := root.build()
+	if err != nil {
+		return Schema{}, err
+	}
+
+	if definitions != nil {
+		schema.Definitions = make(map[string]Schema, len(definitions))
+		for name, def := range definitions {
+			s, err := def.build()
+			if err != nil {
+				return Schema{}, err
+			}
+
+			schema.Definitions[name] = s
+		}
+	}
+
+	if err := schema.Validate(); err != nil {
+		return Schema{}, err
+	}
+
+	return schema, nil
+}
+
+// MustBuild is like Build, but panics if Build returns an error. It simplifies
+// initializing global variables holding schemas.
+func MustBuild(root SchemaBuilder, definitions map[string]SchemaBuilder) Schema {
+	schema, err := Build(root, definitions)
+	if err != nil {
+		panic(err)
+	}
+
+	return schema
+}
+
+func withMetadata(metadata map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		out[k] = v
+	}
+
+	out[key] = value
+	return out
+}
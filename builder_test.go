@@ -0,0 +1,167 @@
+package jtd_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	schema, err := jtd.Build(jtd.Properties(
+		jtd.Required("id", jtd.String().WithMetadata("description", "The ID.")),
+		jtd.Required("createdAt", jtd.Timestamp()),
+		jtd.Optional("tags", jtd.Elements(jtd.Ref("tag"))),
+		jtd.Optional("counts", jtd.Values(jtd.Uint32().Nullable())),
+		jtd.Optional("event", jtd.Discriminator("type", map[string]jtd.PropertiesBuilder{
+			"a": jtd.Properties().AdditionalProperties(),
+			"b": jtd.Properties(jtd.Required("x", jtd.Float64())),
+		})),
+		jtd.Optional("any", jtd.Empty()),
+	), map[string]jtd.SchemaBuilder{
+		"tag": jtd.Enum("RED", "GREEN"),
+	})
+	assert.NoError(t, err)
+
+	var expected jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"tag": { "enum": ["RED", "GREEN"] }
+		},
+		"properties": {
+			"id": { "type": "string", "metadata": { "description": "The ID." } },
+			"createdAt": { "type": "timestamp" }
+		},
+		"optionalProperties": {
+			"tags": { "elements": { "ref": "tag" } },
+			"counts": { "values": { "type": "uint32", "nullable": true } },
+			"event": {
+				"discriminator": "type",
+				"mapping": {
+					"a": { "properties": {}, "additionalProperties": true },
+					"b": { "properties": { "x": { "type": "float64" } } }
+				}
+			},
+			"any": {}
+		}
+	}`), &expected))
+	assert.Equal(t, expected, schema)
+}
+
+func TestBuildTypes(t *testing.T) {
+	builders := map[jtd.Type]jtd.Builder{
+		jtd.TypeBoolean:   jtd.Boolean(),
+		jtd.TypeString:    jtd.String(),
+		jtd.TypeTimestamp: jtd.Timestamp(),
+		jtd.TypeFloat32:   jtd.Float32(),
+		jtd.TypeFloat64:   jtd.Float64(),
+		jtd.TypeInt8:      jtd.Int8(),
+		jtd.TypeUint8:     jtd.Uint8(),
+		jtd.TypeInt16:     jtd.Int16(),
+		jtd.TypeUint16:    jtd.Uint16(),
+		jtd.TypeInt32:     jtd.Int32(),
+		jtd.TypeUint32:    jtd.Uint32(),
+	}
+
+	for typ, b := range builders {
+		schema, err := jtd.Build(b, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, jtd.Schema{Type: typ}, schema)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		root jtd.SchemaBuilder
+		defs map[string]jtd.SchemaBuilder
+		err  error
+	}{
+		{
+			name: "missing definition",
+			root: jtd.Ref("foo"),
+			err:  jtd.ErrNoSuchDefinition,
+		},
+		{
+			name: "empty enum",
+			root: jtd.Elements(jtd.Enum()),
+			err:  jtd.ErrEmptyEnum,
+		},
+		{
+			name: "repeated property",
+			root: jtd.Values(jtd.Properties(
+				jtd.Required("a", jtd.String()),
+				jtd.Required("a", jtd.Boolean()),
+			)),
+			err: jtd.ErrRepeatedProperty,
+		},
+		{
+			name: "shared property",
+			root: jtd.Properties(
+				jtd.Required("a", jtd.String()),
+				jtd.Optional("a", jtd.String()),
+			),
+			err: jtd.ErrSharedProperty,
+		},
+		{
+			name: "repeated discriminator",
+			root: jtd.Discriminator("type", map[string]jtd.PropertiesBuilder{
+				"a": jtd.Properties(jtd.Required("type", jtd.String())),
+			}),
+			err: jtd.ErrMappingRepeatedDiscriminator,
+		},
+		{
+			name: "error in definition",
+			root: jtd.Empty(),
+			defs: map[string]jtd.SchemaBuilder{
+				"a": jtd.Properties(jtd.Optional("b", jtd.Empty()), jtd.Optional("b", jtd.Empty())),
+			},
+			err: jtd.ErrRepeatedProperty,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jtd.Build(tt.root, tt.defs)
+			assert.True(t, errors.Is(err, tt.err), "unexpected error: %v", err)
+		})
+	}
+
+	assert.Panics(t, func() { jtd.MustBuild(jtd.Ref("foo"), nil) })
+}
+
+func TestBuilderImmutable(t *testing.T) {
+	base := jtd.String().WithMetadata("a", 1)
+	nullable := base.Nullable().WithMetadata("b", 2)
+
+	schema := jtd.MustBuild(base, nil)
+	assert.Equal(t, jtd.Schema{Type: jtd.TypeString, Metadata: map[string]interface{}{"a": 1}}, schema)
+
+	schema = jtd.MustBuild(nullable, nil)
+	assert.Equal(t, jtd.Schema{
+		Type:     jtd.TypeString,
+		Nullable: true,
+		Metadata: map[string]interface{}{"a": 1, "b": 2},
+	}, schema)
+}
+
+func ExampleBuild() {
+	schema, err := jtd.Build(jtd.Properties(
+		jtd.Required("name", jtd.String()),
+		jtd.Optional("friends", jtd.Elements(jtd.Ref("user"))),
+	), map[string]jtd.SchemaBuilder{
+		"user": jtd.Properties(jtd.Required("name", jtd.String())),
+	})
+
+	fmt.Println(err)
+	fmt.Println(jtd.Validate(schema, map[string]interface{}{
+		"name":    "a",
+		"friends": []interface{}{map[string]interface{}{}},
+	}))
+	// Output:
+	// <nil>
+	// [{[friends 0] [definitions user properties name]}] <nil>
+}
@@ -0,0 +1,253 @@
+package jtd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Workspace is a set of schemas, keyed by namespace, whose refs may point into
+// each other's definitions.
+//
+// Within a workspace schema, a ref is first resolved against the schema's own
+// definitions. Otherwise, a ref of the form "namespace.name" refers to the
+// definition "name" of the schema in the given namespace, provided that
+// namespace is listed in the schema's "imports" metadata. For example, this
+// schema can use the "money" definition of the "common" namespace:
+//
+//	{
+//	  "metadata": { "imports": ["common"] },
+//	  "properties": { "price": { "ref": "common.money" } }
+//	}
+//
+// Workspace schemas are not valid root schemas on their own. Use Bundle to
+// produce a valid root schema out of them.
+type Workspace map[string]Schema
+
+// MetadataImports is the metadata key that lists the namespaces a workspace
+// schema imports.
+const MetadataImports = "imports"
+
+// WorkspaceFileExt is the extension of schema files read by LoadWorkspace.
+const WorkspaceFileExt = ".jtd.json"
+
+// ErrNoSuchNamespace indicates that a workspace schema imports, or is bundled
+// from, a namespace that is not in its workspace.
+var ErrNoSuchNamespace = errors.New("jtd: no such namespace")
+
+// ErrInvalidImports indicates that a workspace schema has an "imports" metadata
+// value that isn't a list of strings.
+var ErrInvalidImports = errors.New("jtd: imports metadata is not a list of strings")
+
+// ErrDefinitionCollision indicates that two distinct definitions would have the
+// same name in a bundled schema.
+var ErrDefinitionCollision = errors.New("jtd: bundled definitions collide")
+
+// LoadWorkspace reads every file ending in WorkspaceFileExt in dir into a
+// Workspace. Each schema's namespace is its file name, without the extension.
+func LoadWorkspace(dir string) (Workspace, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+WorkspaceFileExt))
+	if err != nil {
+		return nil, err
+	}
+
+	w := Workspace{}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var schema Schema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, fmt.Errorf("jtd: %s: %w", path, err)
+		}
+
+		w[strings.TrimSuffix(filepath.Base(path), WorkspaceFileExt)] = schema
+	}
+
+	return w, nil
+}
+
+// Bundle produces a single root schema out of the schema in w with the given
+// namespace.
+//
+// The returned schema keeps the definitions of the bundled namespace under
+// their original names. Definitions from other namespaces that are reachable
+// from it are added as "namespace.name", and refs are rewritten to match. The
+// "imports" metadata of the root is dropped.
+//
+// Bundle returns an error if a ref cannot be resolved, if two definitions would
+// end up with the same name, or if the resulting schema is not valid according
+// to Schema.Validate.
+func Bundle(w Workspace, namespace string) (Schema, error) {
+	root, ok := w[namespace]
+	if !ok {
+		return Schema{}, fmt.Errorf("%w: %q", ErrNoSuchNamespace, namespace)
+	}
+
+	b := bundler{
+		workspace:   w,
+		root:        namespace,
+		names:       map[string]qualifiedName{},
+		definitions: map[string]Schema{},
+	}
+
+	for name := range root.Definitions {
+		b.names[name] = qualifiedName{namespace, name}
+	}
+
+	out, err := b.rewrite(namespace, root)
+	if err != nil {
+		return Schema{}, err
+	}
+
+	for len(b.queue) > 0 {
+		q := b.queue[0]
+		b.queue = b.queue[1:]
+
+		def, err := b.rewrite(q.namespace, w[q.namespace].Definitions[q.name])
+		if err != nil {
+			return Schema{}, err
+		}
+
+		b.definitions[q.bundledName(namespace)] = def
+	}
+
+	if len(b.definitions) > 0 {
+		definitions := make(map[string]Schema, len(out.Definitions)+len(b.definitions))
+		for name, def := range out.Definitions {
+			definitions[name] = def
+		}
+
+		for name, def := range b.definitions {
+			definitions[name] = def
+		}
+
+		out.Definitions = definitions
+	}
+
+	if _, ok := out.Metadata[MetadataImports]; ok {
+		metadata := make(map[string]interface{}, len(out.Metadata))
+		for k, v := range out.Metadata {
+			if k != MetadataImports {
+				metadata[k] = v
+			}
+		}
+
+		if len(metadata) == 0 {
+			metadata = nil
+		}
+
+		out.Metadata = metadata
+	}
+
+	if err := out.Validate(); err != nil {
+		return Schema{}, err
+	}
+
+	return out, nil
+}
+
+type qualifiedName struct {
+	namespace string
+	name      string
+}
+
+func (q qualifiedName) bundledName(root string) string {
+	if q.namespace == root {
+		return q.name
+	}
+
+	return q.namespace + "." + q.name
+}
+
+type bundler struct {
+	workspace   Workspace
+	root        string
+	names       map[string]qualifiedName
+	definitions map[string]Schema
+	queue       []qualifiedName
+}
+
+// rewrite returns a copy of s, a schema from the given namespace, with all of
+// its refs rewritten to bundled names.
+func (b *bundler) rewrite(namespace string, s Schema) (Schema, error) {
+	imports, err := workspaceImports(b.workspace[namespace])
+	if err != nil {
+		return Schema{}, fmt.Errorf("%w: %q", err, namespace)
+	}
+
+	return Transform(s, func(path []string, s *Schema) error {
+		if s.Ref == nil {
+			return nil
+		}
+
+		q, err := b.resolve(namespace, imports, *s.Ref)
+		if err != nil {
+			return err
+		}
+
+		name := q.bundledName(b.root)
+		if existing, ok := b.names[name]; ok && existing != q {
+			return fmt.Errorf("%w: %q", ErrDefinitionCollision, name)
+		} else if !ok {
+			b.names[name] = q
+			b.queue = append(b.queue, q)
+		}
+
+		s.Ref = &name
+		return nil
+	})
+}
+
+func (b *bundler) resolve(namespace string, imports []string, ref string) (qualifiedName, error) {
+	if _, ok := b.workspace[namespace].Definitions[ref]; ok {
+		return qualifiedName{namespace, ref}, nil
+	}
+
+	if i := strings.Index(ref, "."); i != -1 {
+		for _, imported := range imports {
+			if imported != ref[:i] {
+				continue
+			}
+
+			schema, ok := b.workspace[imported]
+			if !ok {
+				return qualifiedName{}, fmt.Errorf("%w: %q imported from %q", ErrNoSuchNamespace, imported, namespace)
+			}
+
+			if _, ok := schema.Definitions[ref[i+1:]]; ok {
+				return qualifiedName{imported, ref[i+1:]}, nil
+			}
+		}
+	}
+
+	return qualifiedName{}, fmt.Errorf("%w: %q in %q", ErrNoSuchDefinition, ref, namespace)
+}
+
+func workspaceImports(s Schema) ([]string, error) {
+	switch imports := s.Metadata[MetadataImports].(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return imports, nil
+	case []interface{}:
+		out := make([]string, len(imports))
+		for i, v := range imports {
+			s, ok := v.(string)
+			if !ok {
+				return nil, ErrInvalidImports
+			}
+
+			out[i] = s
+		}
+
+		return out, nil
+	default:
+		return nil, ErrInvalidImports
+	}
+}
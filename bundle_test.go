@@ -0,0 +1,111 @@
+package jtd_test
+
+import (
+	"errors"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundle(t *testing.T) {
+	w, err := jtd.LoadWorkspace("testdata/workspace")
+	assert.NoError(t, err)
+	assert.Len(t, w, 2)
+
+	schema, err := jtd.Bundle(w, "orders")
+	assert.NoError(t, err)
+	assert.NoError(t, schema.Validate())
+
+	// Only the definitions reachable from "orders" are bundled.
+	var names []string
+	for name := range schema.Definitions {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{"item", "common.money", "common.currency"}, names)
+
+	assert.Equal(t, "common.money", *schema.Properties["total"].Ref)
+	assert.Equal(t, "common.currency", *schema.Definitions["common.money"].Properties["currency"].Ref)
+	assert.Nil(t, schema.Metadata)
+
+	errs, err := jtd.Validate(schema, map[string]interface{}{
+		"items": []interface{}{},
+		"total": map[string]interface{}{"amount": "1.00", "currency": "JPY"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []jtd.ValidateError{{
+		InstancePath: []string{"total", "currency"},
+		SchemaPath:   []string{"definitions", "common.currency", "enum"},
+	}}, errs)
+}
+
+func TestBundleErrors(t *testing.T) {
+	ref := func(s string) *jtd.Schema { return &jtd.Schema{Ref: &s} }
+
+	testCases := []struct {
+		name      string
+		workspace jtd.Workspace
+		err       error
+	}{
+		{
+			name:      "missing root namespace",
+			workspace: jtd.Workspace{},
+			err:       jtd.ErrNoSuchNamespace,
+		},
+		{
+			name: "ref to unimported namespace",
+			workspace: jtd.Workspace{
+				"root": jtd.Schema{Elements: ref("other.foo")},
+				"other": jtd.Schema{
+					Definitions: map[string]jtd.Schema{"foo": jtd.Schema{}},
+				},
+			},
+			err: jtd.ErrNoSuchDefinition,
+		},
+		{
+			name: "import of missing namespace",
+			workspace: jtd.Workspace{
+				"root": jtd.Schema{
+					Metadata: map[string]interface{}{"imports": []string{"other"}},
+					Elements: ref("other.foo"),
+				},
+			},
+			err: jtd.ErrNoSuchNamespace,
+		},
+		{
+			name: "invalid imports",
+			workspace: jtd.Workspace{
+				"root": jtd.Schema{
+					Metadata: map[string]interface{}{"imports": "other"},
+				},
+			},
+			err: jtd.ErrInvalidImports,
+		},
+		{
+			name: "collision with local definition",
+			workspace: jtd.Workspace{
+				"root": jtd.Schema{
+					Metadata: map[string]interface{}{"imports": []string{"other"}},
+					Definitions: map[string]jtd.Schema{
+						"other.foo": jtd.Schema{Elements: ref("other.bar")},
+					},
+					Ref: ref("other.foo").Ref,
+				},
+				"other": jtd.Schema{
+					Definitions: map[string]jtd.Schema{
+						"foo": jtd.Schema{},
+						"bar": jtd.Schema{Ref: ref("foo").Ref},
+					},
+				},
+			},
+			err: jtd.ErrDefinitionCollision,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jtd.Bundle(tt.workspace, "root")
+			assert.True(t, errors.Is(err, tt.err), "unexpected error: %v", err)
+		})
+	}
+}
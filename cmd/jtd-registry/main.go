@@ -0,0 +1,50 @@
+// Command jtd-registry serves a registry of versioned JSON Typedef schemas over
+// HTTP, storing them as flat files in a directory.
+//
+// Usage:
+//
+//	jtd-registry [-addr host:port] [-dir directory]
+//
+// See registry.Server for the endpoints it exposes, and registry.Client for a
+// Go client of them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/jsontypedef/json-typedef-go/registry"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "`address` to listen on")
+	dir := flag.String("dir", "schemas", "`directory` to store schemas in")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jtd-registry [flags]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "The flags are:")
+		fmt.Fprintln(os.Stderr)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	server, err := registry.NewServer(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jtd-registry: %v\n", err)
+		os.Exit(1)
+	}
+
+	log.Printf("serving %d schemas from %s on %s", len(server.Registry().Names()), *dir, *addr)
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		fmt.Fprintf(os.Stderr, "jtd-registry: %v\n", err)
+		os.Exit(1)
+	}
+}
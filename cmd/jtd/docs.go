@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jsontypedef/json-typedef-go/docgen"
+)
+
+func runDocs(args []string) error {
+	flags := flag.NewFlagSet("docs", flag.ExitOnError)
+	format := flags.String("format", "markdown", "output `format`: markdown or html")
+	title := flags.String("title", "Schema Reference", "`title` of the documentation")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jtd docs [flags] schema.json")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	schema, err := readSchema(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "markdown":
+		return docgen.Markdown(os.Stdout, schema, *title)
+	case "html":
+		return docgen.HTML(os.Stdout, schema, *title)
+	default:
+		return fmt.Errorf("unknown format: %q", *format)
+	}
+}
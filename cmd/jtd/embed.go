@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jsontypedef/json-typedef-go/gogen"
+)
+
+func runEmbed(args []string) error {
+	flags := flag.NewFlagSet("embed", flag.ExitOnError)
+	pkg := flags.String("pkg", os.Getenv("GOPACKAGE"), "`name` of the package to generate, by default the one go generate runs in")
+	out := flags.String("o", "", "`file` to write to, instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jtd embed [flags] schema.jtd.json...")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Each schema is embedded as variables named after its file, e.g.")
+		fmt.Fprintln(os.Stderr, "user-event.jtd.json becomes UserEventSchema and UserEventValidator.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "The flags are:")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 || *pkg == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var schemas []gogen.EmbeddedSchema
+	for _, path := range flags.Args() {
+		schema, err := readSchema(path)
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".jtd.json"), ".json")
+		schemas = append(schemas, gogen.EmbeddedSchema{
+			Name:   gogen.ExportedName(name),
+			Source: filepath.ToSlash(path),
+			Schema: schema,
+		})
+	}
+
+	var b bytes.Buffer
+	if err := gogen.Embed(&b, *pkg, schemas); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := os.Stdout.Write(b.Bytes())
+		return err
+	}
+
+	return ioutil.WriteFile(*out, b.Bytes(), 0o644)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jsontypedef/json-typedef-go/avrogen"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+	"github.com/jsontypedef/json-typedef-go/protogen"
+)
+
+func runProtobuf(args []string) error {
+	name, path := parseExportFlags("protobuf", args)
+	schema, err := readSchema(path)
+	if err != nil {
+		return err
+	}
+
+	losses, err := protogen.Generate(os.Stdout, schema, name)
+	if err != nil {
+		return err
+	}
+
+	for _, loss := range losses {
+		printLoss(loss.SchemaPath, loss.Message)
+	}
+
+	return nil
+}
+
+func runAvro(args []string) error {
+	name, path := parseExportFlags("avro", args)
+	schema, err := readSchema(path)
+	if err != nil {
+		return err
+	}
+
+	losses, err := avrogen.Generate(os.Stdout, schema, name)
+	if err != nil {
+		return err
+	}
+
+	for _, loss := range losses {
+		printLoss(loss.SchemaPath, loss.Message)
+	}
+
+	return nil
+}
+
+// parseExportFlags parses the flags shared by the export commands, and returns
+// the name to give the root schema and the path of the schema.
+func parseExportFlags(cmd string, args []string) (string, string) {
+	flags := flag.NewFlagSet(cmd, flag.ExitOnError)
+	root := flags.String("root", "Root", "`name` of the type for the root schema")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: jtd %s [flags] schema.json\n", cmd)
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Parts of the schema that cannot be exported exactly are reported on stderr.")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	return *root, flags.Arg(0)
+}
+
+func printLoss(path []string, message string) {
+	fmt.Fprintf(os.Stderr, "lossy: %s: %s\n", schemautil.Pointer(path), message)
+}
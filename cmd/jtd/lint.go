@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jsontypedef/json-typedef-go/lint"
+)
+
+func runLint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	enable := flags.String("enable", "", "comma-separated `rules` to enable in addition to the defaults")
+	disable := flags.String("disable", "", "comma-separated `rules` to disable")
+	failOn := flags.String("fail-on", "warning", "exit with an error if there are findings of this `severity` or above")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jtd lint [flags] schema.json...")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "The built-in rules are:")
+		fmt.Fprintln(os.Stderr)
+		for _, rule := range lint.BuiltinRules() {
+			fmt.Fprintf(os.Stderr, "\t%s (%s)\n", rule.Name, rule.Severity)
+		}
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "The flags are:")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	threshold, err := lint.ParseSeverity(*failOn)
+	if err != nil {
+		return err
+	}
+
+	enabled := map[string]bool{}
+	for _, rule := range lint.DefaultRules() {
+		enabled[rule.Name] = true
+	}
+
+	if err := setRules(enabled, *enable, true); err != nil {
+		return err
+	}
+
+	if err := setRules(enabled, *disable, false); err != nil {
+		return err
+	}
+
+	var rules []lint.Rule
+	for _, rule := range lint.BuiltinRules() {
+		if enabled[rule.Name] {
+			rules = append(rules, rule)
+		}
+	}
+
+	failed := false
+	for _, path := range flags.Args() {
+		schema, err := readSchema(path)
+		if err != nil {
+			return err
+		}
+
+		for _, finding := range lint.Lint(schema, rules...) {
+			fmt.Printf("%s: %s\n", path, finding)
+			failed = failed || finding.Severity >= threshold
+		}
+	}
+
+	if failed {
+		return errors.New("found problems")
+	}
+
+	return nil
+}
+
+// setRules sets the rules named in the comma-separated list names to enable.
+func setRules(enabled map[string]bool, names string, enable bool) error {
+	for _, name := range strings.Split(names, ",") {
+		if name == "" {
+			continue
+		}
+
+		known := false
+		for _, rule := range lint.BuiltinRules() {
+			known = known || rule.Name == name
+		}
+
+		if !known {
+			return fmt.Errorf("unknown rule: %q", name)
+		}
+
+		enabled[name] = enable
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+// Command jtd is a collection of tools for working with JSON Typedef schemas.
+//
+// Usage:
+//
+//	jtd <command> [arguments]
+//
+// Run "jtd <command> -h" for help on a particular command.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"lint", "check schemas against style rules", runLint},
+	{"docs", "generate documentation from a schema", runDocs},
+	{"typescript", "generate TypeScript declarations from a schema", runTypeScript},
+	{"protobuf", "export a schema as a proto3 file", runProtobuf},
+	{"avro", "export a schema as an Avro schema", runAvro},
+	{"openapi", "export schemas as OpenAPI 3.1 components", runOpenAPI},
+	{"embed", "generate Go code that embeds schemas", runEmbed},
+	{"validator", "generate a Go validation function for a schema", runValidator},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "jtd %s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "jtd: unknown command %q\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: jtd <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "The commands are:")
+	fmt.Fprintln(os.Stderr)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-10s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+// readSchema reads a root schema from path, or from stdin if path is "-", and
+// makes sure it is valid.
+func readSchema(path string) (jtd.Schema, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+
+	if err != nil {
+		return jtd.Schema{}, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var schema jtd.Schema
+	if err := decoder.Decode(&schema); err != nil {
+		return jtd.Schema{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := schema.Validate(); err != nil {
+		return jtd.Schema{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/openapi"
+)
+
+func runOpenAPI(args []string) error {
+	flags := flag.NewFlagSet("openapi", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jtd openapi Name=schema.json...")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Outputs an OpenAPI 3.1 components object with a schema for each Name.")
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	schemas := map[string]jtd.Schema{}
+	for _, arg := range flags.Args() {
+		i := strings.Index(arg, "=")
+		if i == -1 {
+			return fmt.Errorf("argument is not of the form Name=schema.json: %q", arg)
+		}
+
+		schema, err := readSchema(arg[i+1:])
+		if err != nil {
+			return err
+		}
+
+		schemas[arg[:i]] = schema
+	}
+
+	components, err := openapi.Components(schemas)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{
+		"components": map[string]interface{}{"schemas": components},
+	})
+}
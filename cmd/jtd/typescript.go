@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jsontypedef/json-typedef-go/tsgen"
+)
+
+func runTypeScript(args []string) error {
+	flags := flag.NewFlagSet("typescript", flag.ExitOnError)
+	root := flags.String("root", "Root", "`name` of the type for the root schema")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jtd typescript [flags] schema.json")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	schema, err := readSchema(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return tsgen.Generate(os.Stdout, schema, *root)
+}
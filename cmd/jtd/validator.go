@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jsontypedef/json-typedef-go/gogen"
+)
+
+func runValidator(args []string) error {
+	flags := flag.NewFlagSet("validator", flag.ExitOnError)
+	pkg := flags.String("pkg", os.Getenv("GOPACKAGE"), "`name` of the package to generate, by default the one go generate runs in")
+	name := flags.String("name", "", "`name` of the schema, by default taken from its file name")
+	out := flags.String("o", "", "`file` to write to, instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jtd validator [flags] schema.jtd.json")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "The generated function is named after the schema, e.g. order.jtd.json")
+		fmt.Fprintln(os.Stderr, "becomes ValidateOrder.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "The flags are:")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || *pkg == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	schema, err := readSchema(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *name == "" {
+		base := filepath.Base(flags.Arg(0))
+		*name = gogen.ExportedName(strings.TrimSuffix(strings.TrimSuffix(base, ".jtd.json"), ".json"))
+	}
+
+	var b bytes.Buffer
+	if err := gogen.GenerateValidator(&b, *pkg, *name, schema); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := os.Stdout.Write(b.Bytes())
+		return err
+	}
+
+	return ioutil.WriteFile(*out, b.Bytes(), 0o644)
+}
@@ -0,0 +1,284 @@
+package jtd
+
+import (
+	"math"
+	"strconv"
+)
+
+// Coercion identifies a value that Coerce converted to match its schema.
+type Coercion struct {
+	// Path to the part of the instance that was converted.
+	InstancePath []string
+
+	// Path to the part of the schema that the value was converted to match.
+	SchemaPath []string
+}
+
+// CoerceResult is the result of Coerce.
+type CoerceResult struct {
+	// Instance is the instance, with values converted to match the schema where
+	// possible.
+	Instance interface{}
+
+	// Coerced lists the values that were converted.
+	Coerced []Coercion
+
+	// Failed lists the values that did not match their schema and could not be
+	// converted. These use the same paths that Validate would use to report
+	// them.
+	Failed []ValidateError
+}
+
+// Coerce converts the values in instance that don't match schema, but that
+// unambiguously represent a value that would, such as the ones that come from
+// query strings, CSV files, or environment variables.
+//
+// Coerce makes these conversions:
+//
+//	"true" or "false"         to a boolean, for the boolean type
+//	a string with a number    to that number, for the float and integer types,
+//	                          if it is in range of the type
+//	anything but null         to a one-element array, for the elements form
+//	or an array
+//
+// Coerce reports the values it converted, and the values that don't match
+// schema but which it could not convert, in the result. It does not report
+// missing or additional properties, as those cannot be fixed by converting
+// values. Containers within instance are copied as necessary; instance itself
+// is never modified.
+//
+// Coerce has no MaxDepth setting. Following refs is only ever cut short by
+// one of the cycles that Schema.RefCycles reports, for which Coerce returns
+// ErrUnproductiveRefCycle.
+func Coerce(schema Schema, instance interface{}) (CoerceResult, error) {
+	state := coerceState{
+		validateState: validateState{
+			Errors:      []ValidateError{},
+			SchemaBases: []int{0},
+			Root:        schema,
+		},
+		Coerced: []Coercion{},
+	}
+
+	out, err := coerce(&state, schema, instance)
+	if err != nil {
+		return CoerceResult{}, err
+	}
+
+	return CoerceResult{Instance: out, Coerced: state.Coerced, Failed: state.Errors}, nil
+}
+
+type coerceState struct {
+	validateState
+	Coerced []Coercion
+}
+
+func (cs *coerceState) pushCoercion() {
+	instancePath, schemaPath := cs.paths()
+	cs.Coerced = append(cs.Coerced, Coercion{
+		InstancePath: instancePath,
+		SchemaPath:   schemaPath,
+	})
+}
+
+func coerce(state *coerceState, schema Schema, instance interface{}) (interface{}, error) {
+	if schema.Nullable && instance == nil {
+		return nil, nil
+	}
+
+	// The errors from validate and pushError are only ever errMaxErrorsReached,
+	// which can't happen here because MaxErrors is zero. So they are ignored
+	// below.
+	switch schema.Form() {
+	case FormRef:
+		if state.Refs.loops(*schema.Ref, len(state.InstanceTokens)) {
+			return nil, ErrUnproductiveRefCycle
+		}
+
+		state.Refs = append(state.Refs, refFrame{Name: *schema.Ref, InstanceDepth: len(state.InstanceTokens)})
+		state.pushRef(*schema.Ref)
+		out, err := coerce(state, state.Root.Definitions[*schema.Ref], instance)
+		state.popRef()
+		state.Refs = state.Refs[:len(state.Refs)-1]
+
+		return out, err
+	case FormType:
+		// Find out whether instance already matches by validating it.
+		errs := len(state.Errors)
+		validate(&state.validateState, schema, instance, "")
+		if len(state.Errors) == errs {
+			return instance, nil
+		}
+
+		s, ok := instance.(string)
+		if !ok {
+			return instance, nil
+		}
+
+		var out interface{}
+		switch schema.Type {
+		case TypeBoolean:
+			switch s {
+			case "true":
+				out = true
+			case "false":
+				out = false
+			}
+		case TypeFloat32, TypeFloat64, TypeInt8, TypeUint8, TypeInt16, TypeUint16, TypeInt32, TypeUint32:
+			if n, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+				out = n
+			}
+		}
+
+		if out == nil {
+			return instance, nil
+		}
+
+		// Make sure the converted value matches, e.g. that it is in range.
+		state.Errors = state.Errors[:errs]
+		validate(&state.validateState, schema, out, "")
+		if len(state.Errors) != errs {
+			return instance, nil
+		}
+
+		state.pushSchemaToken("type")
+		state.pushCoercion()
+		state.popSchemaToken()
+
+		return out, nil
+	case FormEnum:
+		// There's nothing to convert to, but validating reports the failure if
+		// the instance doesn't match.
+		validate(&state.validateState, schema, instance, "")
+		return instance, nil
+	case FormElements:
+		state.pushSchemaToken("elements")
+		defer state.popSchemaToken()
+
+		arr, ok := instance.([]interface{})
+		if !ok {
+			if instance == nil {
+				state.pushError()
+				return instance, nil
+			}
+
+			state.pushCoercion()
+			arr = []interface{}{instance}
+		}
+
+		out := make([]interface{}, len(arr))
+		for i, subInstance := range arr {
+			state.pushInstanceIndex(i)
+			v, err := coerce(state, *schema.Elements, subInstance)
+			state.popInstanceToken()
+
+			if err != nil {
+				return nil, err
+			}
+
+			out[i] = v
+		}
+
+		return out, nil
+	case FormProperties:
+		obj, ok := instance.(map[string]interface{})
+		if !ok {
+			if schema.Properties != nil {
+				state.pushSchemaToken("properties")
+			} else {
+				state.pushSchemaToken("optionalProperties")
+			}
+
+			state.pushError()
+			state.popSchemaToken()
+			return instance, nil
+		}
+
+		out := make(map[string]interface{}, len(obj))
+		for key, subInstance := range obj {
+			out[key] = subInstance
+		}
+
+		for _, keyword := range []string{"properties", "optionalProperties"} {
+			properties := schema.Properties
+			if keyword == "optionalProperties" {
+				properties = schema.OptionalProperties
+			}
+
+			state.pushSchemaToken(keyword)
+			for key, subSchema := range properties {
+				subInstance, ok := obj[key]
+				if !ok {
+					continue
+				}
+
+				state.pushSchemaToken(key)
+				state.pushInstanceToken(key)
+				v, err := coerce(state, subSchema, subInstance)
+				state.popInstanceToken()
+				state.popSchemaToken()
+
+				if err != nil {
+					return nil, err
+				}
+
+				out[key] = v
+			}
+			state.popSchemaToken()
+		}
+
+		return out, nil
+	case FormValues:
+		state.pushSchemaToken("values")
+		defer state.popSchemaToken()
+
+		obj, ok := instance.(map[string]interface{})
+		if !ok {
+			state.pushError()
+			return instance, nil
+		}
+
+		out := make(map[string]interface{}, len(obj))
+		for key, subInstance := range obj {
+			state.pushInstanceToken(key)
+			v, err := coerce(state, *schema.Values, subInstance)
+			state.popInstanceToken()
+
+			if err != nil {
+				return nil, err
+			}
+
+			out[key] = v
+		}
+
+		return out, nil
+	case FormDiscriminator:
+		obj, ok := instance.(map[string]interface{})
+		if !ok {
+			validate(&state.validateState, schema, instance, "")
+			return instance, nil
+		}
+
+		tag, ok := obj[schema.Discriminator].(string)
+		if !ok {
+			validate(&state.validateState, schema, instance, "")
+			return instance, nil
+		}
+
+		mapping, ok := schema.Mapping[tag]
+		if !ok {
+			validate(&state.validateState, schema, instance, "")
+			return instance, nil
+		}
+
+		state.pushSchemaToken("mapping")
+		state.pushSchemaToken(tag)
+		out, err := coerce(state, mapping, instance)
+		state.popSchemaToken()
+		state.popSchemaToken()
+
+		return out, err
+	default:
+		return instance, nil
+	}
+}
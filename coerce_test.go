@@ -0,0 +1,143 @@
+package jtd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoerce(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"count": { "type": "uint8" }
+		},
+		"properties": {
+			"enabled": { "type": "boolean" },
+			"ratio": { "type": "float64" },
+			"count": { "ref": "count" },
+			"tags": { "elements": { "type": "string" } },
+			"ids": { "elements": { "type": "int32" } },
+			"limits": { "values": { "type": "int16", "nullable": true } },
+			"color": { "enum": ["RED", "GREEN"] },
+			"event": {
+				"discriminator": "type",
+				"mapping": {
+					"a": { "properties": { "n": { "type": "uint32" } } }
+				}
+			}
+		}
+	}`), &schema))
+
+	var instance interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"enabled": "true",
+		"ratio": "0.5",
+		"count": "300",
+		"tags": "x",
+		"ids": ["1", 2, "3.5", "NaN"],
+		"limits": { "a": "-7", "b": null, "c": "yes" },
+		"color": "BLUE",
+		"event": { "type": "a", "n": "10" },
+		"extra": "1"
+	}`), &instance))
+
+	result, err := jtd.Coerce(schema, instance)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"enabled": true,
+		"ratio":   0.5,
+		"count":   "300",
+		"tags":    []interface{}{"x"},
+		"ids":     []interface{}{1.0, 2.0, "3.5", "NaN"},
+		"limits":  map[string]interface{}{"a": -7.0, "b": nil, "c": "yes"},
+		"color":   "BLUE",
+		"event":   map[string]interface{}{"type": "a", "n": 10.0},
+		"extra":   "1",
+	}, result.Instance)
+
+	assert.ElementsMatch(t, []jtd.Coercion{
+		{InstancePath: []string{"enabled"}, SchemaPath: []string{"properties", "enabled", "type"}},
+		{InstancePath: []string{"ratio"}, SchemaPath: []string{"properties", "ratio", "type"}},
+		{InstancePath: []string{"tags"}, SchemaPath: []string{"properties", "tags", "elements"}},
+		{InstancePath: []string{"ids", "0"}, SchemaPath: []string{"properties", "ids", "elements", "type"}},
+		{InstancePath: []string{"limits", "a"}, SchemaPath: []string{"properties", "limits", "values", "type"}},
+		{InstancePath: []string{"event", "n"}, SchemaPath: []string{"properties", "event", "mapping", "a", "properties", "n", "type"}},
+	}, result.Coerced)
+
+	assert.ElementsMatch(t, []jtd.ValidateError{
+		{InstancePath: []string{"count"}, SchemaPath: []string{"definitions", "count", "type"}},
+		{InstancePath: []string{"ids", "2"}, SchemaPath: []string{"properties", "ids", "elements", "type"}},
+		{InstancePath: []string{"ids", "3"}, SchemaPath: []string{"properties", "ids", "elements", "type"}},
+		{InstancePath: []string{"limits", "c"}, SchemaPath: []string{"properties", "limits", "values", "type"}},
+		{InstancePath: []string{"color"}, SchemaPath: []string{"properties", "color", "enum"}},
+	}, result.Failed)
+
+	// The original instance is left untouched.
+	assert.Equal(t, "true", instance.(map[string]interface{})["enabled"])
+}
+
+func TestCoerceMismatchedForms(t *testing.T) {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"a": jtd.Schema{Elements: &jtd.Schema{}},
+			"b": jtd.Schema{Values: &jtd.Schema{}},
+			"c": jtd.Schema{Discriminator: "type", Mapping: map[string]jtd.Schema{}},
+			"d": jtd.Schema{Properties: map[string]jtd.Schema{}},
+		},
+	}
+
+	result, err := jtd.Coerce(schema, map[string]interface{}{
+		"a": nil,
+		"b": "x",
+		"c": map[string]interface{}{"type": "z"},
+		"d": 1.0,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Coerced)
+	assert.ElementsMatch(t, []jtd.ValidateError{
+		{InstancePath: []string{"a"}, SchemaPath: []string{"properties", "a", "elements"}},
+		{InstancePath: []string{"b"}, SchemaPath: []string{"properties", "b", "values"}},
+		{InstancePath: []string{"c", "type"}, SchemaPath: []string{"properties", "c", "mapping"}},
+		{InstancePath: []string{"d"}, SchemaPath: []string{"properties", "d", "properties"}},
+	}, result.Failed)
+}
+
+func TestCoerceUnproductiveRefCycle(t *testing.T) {
+	loop := "loop"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{"loop": jtd.Schema{Ref: &loop}},
+		Ref:         &loop,
+	}
+
+	_, err := jtd.Coerce(schema, nil)
+	assert.Equal(t, jtd.ErrUnproductiveRefCycle, err)
+}
+
+func ExampleCoerce() {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"port":    jtd.Schema{Type: jtd.TypeUint16},
+			"verbose": jtd.Schema{Type: jtd.TypeBoolean},
+			"hosts":   jtd.Schema{Elements: &jtd.Schema{Type: jtd.TypeString}},
+		},
+	}
+
+	// As if read from environment variables.
+	instance := map[string]interface{}{
+		"port":    "8080",
+		"verbose": "false",
+		"hosts":   "localhost",
+	}
+
+	result, _ := jtd.Coerce(schema, instance)
+	fmt.Println(result.Instance)
+	fmt.Println(len(result.Coerced), result.Failed)
+	// Output:
+	// map[hosts:[localhost] port:8080 verbose:false]
+	// 3 []
+}
@@ -0,0 +1,422 @@
+package jtd
+
+import "fmt"
+
+// CompiledSchema is a root schema prepared for validating instances. Validating
+// against a CompiledSchema skips the work that Validate otherwise does on every
+// call, such as determining the form of each subschema and looking up
+// definitions by name. Validating an instance that is valid against a
+// CompiledSchema does not allocate memory, and neither does IsValid.
+//
+// A CompiledSchema is safe for concurrent use by multiple goroutines.
+type CompiledSchema struct {
+	schema Schema
+	root   *compiledNode
+
+	// definitions holds, for each definition, a node of the ref form that
+	// refers to it.
+	definitions map[string]*compiledNode
+}
+
+// Compile prepares schema, a root schema, for validating instances. It returns
+// the same errors as Schema.Validate if schema is not valid.
+func Compile(schema Schema) (*CompiledSchema, error) {
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+
+	return compile(schema), nil
+}
+
+// MustCompile is like Compile, but panics if schema is not valid. It is meant
+// for schemas that are known to be valid, such as ones embedded in generated
+// code.
+func MustCompile(schema Schema) *CompiledSchema {
+	c, err := Compile(schema)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// Schema returns the schema c was compiled from.
+func (c *CompiledSchema) Schema() Schema {
+	return c.schema
+}
+
+// Validate validates an instance against c. It is equivalent to calling
+// Validate with the schema c was compiled from.
+func (c *CompiledSchema) Validate(instance interface{}, opts ...ValidateOption) ([]ValidateError, error) {
+	settings := applyValidateOptions(opts)
+
+	return c.ValidateWithSettings(settings, instance)
+}
+
+// ValidateWithSettings validates an instance against c, using a set of
+// settings. It is equivalent to calling ValidateWithSettings with the schema c
+// was compiled from.
+func (c *CompiledSchema) ValidateWithSettings(settings ValidateSettings, instance interface{}) ([]ValidateError, error) {
+	return validateNode(settings, c.root, instance)
+}
+
+// ValidateDefinition validates an instance against the definition of c with
+// the given name. It is equivalent to calling ValidateDefinition with the
+// schema c was compiled from.
+func (c *CompiledSchema) ValidateDefinition(name string, instance interface{}, opts ...ValidateOption) ([]ValidateError, error) {
+	node, ok := c.definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoSuchDefinition, name)
+	}
+
+	return validateNode(applyValidateOptions(opts), node, instance)
+}
+
+func validateNode(settings ValidateSettings, node *compiledNode, instance interface{}) ([]ValidateError, error) {
+	state := getValidateState(settings)
+	defer putValidateState(state)
+
+	// errMaxErrorsReached is just an internal error used to quickly abort further
+	// validation. It is not an actual error for the end user, just a
+	// circuit-breaker used by validate internally.
+	if err := validateCompiled(state, node, instance, nil); err != nil && err != errMaxErrorsReached {
+		return nil, err
+	}
+
+	return state.errors(), nil
+}
+
+// IsValid returns whether instance is valid against c. It is equivalent to
+// calling IsValid with the schema c was compiled from.
+func (c *CompiledSchema) IsValid(instance interface{}, opts ...ValidateOption) (bool, error) {
+	state := getValidateState(applyValidateOptions(opts))
+	defer putValidateState(state)
+
+	state.StopAtFirst = true
+	if err := validateCompiled(state, c.root, instance, nil); err != nil && err != errMaxErrorsReached {
+		return false, err
+	}
+
+	return !state.Invalid, nil
+}
+
+// ValidateDetailed validates an instance against c, and reports details about
+// the validation. It is equivalent to calling ValidateDetailed with the schema
+// c was compiled from.
+func (c *CompiledSchema) ValidateDetailed(instance interface{}, opts ...ValidateOption) (ValidateResult, error) {
+	state := getDetailedValidateState(opts)
+	defer putValidateState(state)
+
+	if err := validateCompiled(state, c.root, instance, nil); err != nil && err != errMaxErrorsReached {
+		return ValidateResult{}, err
+	}
+
+	return state.detailedResult(), nil
+}
+
+// validateCompiled is like validate, but validates against a compiled node.
+func validateCompiled(state *validateState, node *compiledNode, instance interface{}, parentTag *string) error {
+	state.NodesVisited++
+
+	if node.nullable && instance == nil {
+		return nil
+	}
+
+	switch node.form {
+	case FormEmpty:
+		return nil
+	case FormRef:
+		if len(state.SchemaBases) == state.Settings.MaxDepth {
+			return ErrMaxDepthExceeded
+		}
+
+		if state.Settings.MaxDepth == 0 && node.refCycle && !(instance == nil && node.refCycleNullable) {
+			return ErrUnproductiveRefCycle
+		}
+
+		state.pushRef(node.ref)
+		if err := validateCompiled(state, node.definition, instance, nil); err != nil {
+			return err
+		}
+		state.popRef()
+	case FormType:
+		return validateType(state, node.typ, instance)
+	case FormEnum:
+		return validateEnum(state, node.enum, instance)
+	case FormElements:
+		state.pushSchemaToken("elements")
+		if arr, ok := instance.([]interface{}); ok {
+			for i, subInstance := range arr {
+				state.pushInstanceIndex(i)
+				if err := validateCompiled(state, node.elements, subInstance, nil); err != nil {
+					return err
+				}
+				state.popInstanceToken()
+			}
+		} else {
+			if err := state.pushError(); err != nil {
+				return err
+			}
+		}
+		state.popSchemaToken()
+	case FormProperties:
+		if obj, ok := instance.(map[string]interface{}); ok {
+			state.pushSchemaToken("properties")
+			for key, subSchema := range node.properties {
+				state.pushSchemaToken(key)
+				if subInstance, ok := obj[key]; ok {
+					state.pushInstanceToken(key)
+					if err := validateCompiled(state, subSchema, subInstance, nil); err != nil {
+						return err
+					}
+					state.popInstanceToken()
+				} else {
+					if err := state.pushError(); err != nil {
+						return err
+					}
+				}
+				state.popSchemaToken()
+			}
+			state.popSchemaToken()
+
+			state.pushSchemaToken("optionalProperties")
+			for key, subSchema := range node.optionalProperties {
+				state.pushSchemaToken(key)
+				if subInstance, ok := obj[key]; ok {
+					state.pushInstanceToken(key)
+					if err := validateCompiled(state, subSchema, subInstance, nil); err != nil {
+						return err
+					}
+					state.popInstanceToken()
+				}
+				state.popSchemaToken()
+			}
+			state.popSchemaToken()
+
+			if !node.additionalProperties {
+				for key := range obj {
+					if parentTag != nil && key == *parentTag {
+						continue
+					}
+
+					requiredOk := false
+					optionalOk := false
+
+					if node.properties != nil {
+						_, requiredOk = node.properties[key]
+					}
+
+					if node.optionalProperties != nil {
+						_, optionalOk = node.optionalProperties[key]
+					}
+
+					if !requiredOk && !optionalOk {
+						state.pushInstanceToken(key)
+						if err := state.pushError(); err != nil {
+							return err
+						}
+						state.popInstanceToken()
+					}
+				}
+			}
+		} else {
+			if node.properties != nil {
+				state.pushSchemaToken("properties")
+			} else {
+				state.pushSchemaToken("optionalProperties")
+			}
+
+			if err := state.pushError(); err != nil {
+				return err
+			}
+
+			state.popSchemaToken()
+		}
+	case FormValues:
+		state.pushSchemaToken("values")
+		if obj, ok := instance.(map[string]interface{}); ok {
+			for key, subInstance := range obj {
+				state.pushInstanceToken(key)
+				if err := validateCompiled(state, node.values, subInstance, nil); err != nil {
+					return err
+				}
+				state.popInstanceToken()
+			}
+		} else {
+			if err := state.pushError(); err != nil {
+				return err
+			}
+		}
+		state.popSchemaToken()
+	case FormDiscriminator:
+		if obj, ok := instance.(map[string]interface{}); ok {
+			if tag, ok := obj[node.discriminator]; ok {
+				if tagStr, ok := tag.(string); ok {
+					if mapping, ok := node.mapping[tagStr]; ok {
+						state.pushSchemaToken("mapping")
+						state.pushSchemaToken(tagStr)
+
+						if err := validateCompiled(state, mapping, instance, &node.discriminator); err != nil {
+							return err
+						}
+
+						state.popSchemaToken()
+						state.popSchemaToken()
+					} else {
+						state.pushSchemaToken("mapping")
+						state.pushInstanceToken(node.discriminator)
+						if err := state.pushError(); err != nil {
+							return err
+						}
+						state.popInstanceToken()
+						state.popSchemaToken()
+					}
+				} else {
+					state.pushSchemaToken("discriminator")
+					state.pushInstanceToken(node.discriminator)
+					if err := state.pushError(); err != nil {
+						return err
+					}
+					state.popInstanceToken()
+					state.popSchemaToken()
+				}
+			} else {
+				state.pushSchemaToken("discriminator")
+				if err := state.pushError(); err != nil {
+					return err
+				}
+				state.popSchemaToken()
+			}
+		} else {
+			state.pushSchemaToken("discriminator")
+			if err := state.pushError(); err != nil {
+				return err
+			}
+			state.popSchemaToken()
+		}
+	}
+
+	return nil
+}
+
+// compiledNode is a subschema of a CompiledSchema. Its form is determined once,
+// and refs point directly at the node of their definition.
+type compiledNode struct {
+	form     Form
+	nullable bool
+
+	ref        string
+	definition *compiledNode
+
+	// refCycle is whether following refs from this node of the ref form would
+	// loop forever. If refCycleNullable is also true, one of the definitions
+	// along the way is nullable, which cuts the cycle short for null instances.
+	refCycle         bool
+	refCycleNullable bool
+
+	typ                  Type
+	enum                 []string
+	elements             *compiledNode
+	properties           map[string]*compiledNode
+	optionalProperties   map[string]*compiledNode
+	additionalProperties bool
+	values               *compiledNode
+	discriminator        string
+	mapping              map[string]*compiledNode
+}
+
+// compile compiles schema without checking that it is valid. Refs to
+// definitions that don't exist compile to the empty form.
+func compile(schema Schema) *CompiledSchema {
+	c := compiler{definitions: make(map[string]*compiledNode, len(schema.Definitions))}
+	for name := range schema.Definitions {
+		c.definitions[name] = &compiledNode{}
+	}
+
+	for name, def := range schema.Definitions {
+		c.fill(c.definitions[name], def)
+	}
+
+	root := &compiledNode{}
+	c.fill(root, schema)
+
+	definitions := make(map[string]*compiledNode, len(schema.Definitions))
+	for name, def := range c.definitions {
+		definitions[name] = &compiledNode{form: FormRef, ref: name, definition: def}
+		c.refs = append(c.refs, definitions[name])
+	}
+
+	for _, node := range c.refs {
+		seen := map[*compiledNode]bool{}
+		for next := node.definition; ; next = next.definition {
+			if seen[next] {
+				node.refCycle = true
+				break
+			}
+
+			seen[next] = true
+			node.refCycleNullable = node.refCycleNullable || next.nullable
+
+			if next.form != FormRef {
+				break
+			}
+		}
+	}
+
+	return &CompiledSchema{schema: schema, root: root, definitions: definitions}
+}
+
+type compiler struct {
+	definitions map[string]*compiledNode
+	refs        []*compiledNode
+}
+
+func (c *compiler) fill(node *compiledNode, s Schema) {
+	node.form = s.Form()
+	node.nullable = s.Nullable
+
+	switch node.form {
+	case FormRef:
+		node.ref = *s.Ref
+		node.definition = c.definitions[*s.Ref]
+		if node.definition == nil {
+			node.definition = &compiledNode{form: FormEmpty}
+		}
+
+		c.refs = append(c.refs, node)
+	case FormType:
+		node.typ = s.Type
+	case FormEnum:
+		node.enum = s.Enum
+	case FormElements:
+		node.elements = c.node(*s.Elements)
+	case FormProperties:
+		node.properties = c.nodes(s.Properties)
+		node.optionalProperties = c.nodes(s.OptionalProperties)
+		node.additionalProperties = s.AdditionalProperties
+	case FormValues:
+		node.values = c.node(*s.Values)
+	case FormDiscriminator:
+		node.discriminator = s.Discriminator
+		node.mapping = c.nodes(s.Mapping)
+	}
+}
+
+func (c *compiler) node(s Schema) *compiledNode {
+	node := &compiledNode{}
+	c.fill(node, s)
+	return node
+}
+
+func (c *compiler) nodes(schemas map[string]Schema) map[string]*compiledNode {
+	if schemas == nil {
+		return nil
+	}
+
+	out := make(map[string]*compiledNode, len(schemas))
+	for name, s := range schemas {
+		out[name] = c.node(s)
+	}
+
+	return out
+}
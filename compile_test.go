@@ -0,0 +1,108 @@
+package jtd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"node": {
+				"properties": {
+					"value": { "type": "uint8" },
+					"children": { "elements": { "ref": "node" } }
+				}
+			}
+		},
+		"ref": "node"
+	}`), &schema))
+
+	compiled, err := jtd.Compile(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, schema, compiled.Schema())
+
+	instances := []interface{}{
+		map[string]interface{}{"value": 1.0, "children": []interface{}{}},
+		map[string]interface{}{"value": 1.0, "children": []interface{}{
+			map[string]interface{}{"value": 256.0, "children": []interface{}{}},
+			map[string]interface{}{"value": 2.0},
+		}},
+		"foo",
+	}
+
+	for _, instance := range instances {
+		expected, err := jtd.Validate(schema, instance)
+		assert.NoError(t, err)
+
+		actual, err := compiled.Validate(instance)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+
+		valid, err := compiled.IsValid(instance)
+		assert.NoError(t, err)
+		assert.Equal(t, len(expected) == 0, valid)
+	}
+
+	errs, err := compiled.Validate(instances[1], jtd.WithMaxErrors(1))
+	assert.NoError(t, err)
+	assert.Len(t, errs, 1)
+
+	_, err = compiled.Validate(instances[1], jtd.WithMaxDepth(1))
+	assert.Equal(t, jtd.ErrMaxDepthExceeded, err)
+}
+
+func TestCompileInvalid(t *testing.T) {
+	foo := "foo"
+	_, err := jtd.Compile(jtd.Schema{Ref: &foo})
+	assert.Equal(t, jtd.ErrNoSuchDefinition, err)
+
+	assert.Panics(t, func() { jtd.MustCompile(jtd.Schema{Ref: &foo}) })
+	assert.NotNil(t, jtd.MustCompile(jtd.Schema{}))
+}
+
+func TestCompileUnproductiveRefCycle(t *testing.T) {
+	a := "a"
+	b := "b"
+	compiled, err := jtd.Compile(jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"a": jtd.Schema{Ref: &b},
+			"b": jtd.Schema{Ref: &a, Nullable: true},
+		},
+		Elements: &jtd.Schema{Ref: &a},
+	})
+	assert.NoError(t, err)
+
+	errs, err := compiled.Validate([]interface{}{nil})
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+
+	_, err = compiled.Validate([]interface{}{nil, "foo"})
+	assert.Equal(t, jtd.ErrUnproductiveRefCycle, err)
+}
+
+func ExampleCompile() {
+	schema := jtd.Schema{
+		Elements: &jtd.Schema{Type: jtd.TypeString},
+	}
+
+	compiled, err := jtd.Compile(schema)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, instance := range []interface{}{
+		[]interface{}{"a", "b"},
+		[]interface{}{"a", 1.0},
+	} {
+		fmt.Println(compiled.Validate(instance))
+	}
+	// Output:
+	// [] <nil>
+	// [{[1] [elements type]}] <nil>
+}
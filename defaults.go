@@ -0,0 +1,174 @@
+package jtd
+
+// MetadataDefault is the metadata key that ApplyDefaults takes default values
+// from.
+const MetadataDefault = "default"
+
+// ApplyDefaults returns a copy of instance where every missing optional
+// property is filled in with the "default" value in the metadata of its
+// schema, if it has one.
+//
+// ApplyDefaults looks for missing optional properties throughout instance,
+// following elements, values, refs, and discriminator mappings. Defaults are
+// inserted as-is; defaults of properties within defaults are not applied
+// further. Parts of instance that don't match schema are left untouched, so
+// that a subsequent call to Validate can report them. Every map and slice
+// that ApplyDefaults looks into is copied, whether or not anything was filled
+// in within it, so instance itself is never modified.
+//
+// schema should be valid according to Schema.Validate, which makes sure that
+// defaults are themselves valid. That doesn't rule out ref cycles that never
+// descend into the instance, though; if instance reaches one, ApplyDefaults
+// stops with ErrUnproductiveRefCycle.
+func ApplyDefaults(schema Schema, instance interface{}) (interface{}, error) {
+	state := defaultsState{Root: schema}
+	return applyDefaults(&state, schema, instance)
+}
+
+type defaultsState struct {
+	Root  Schema
+	Refs  refStack
+	Depth int
+}
+
+func applyDefaults(state *defaultsState, schema Schema, instance interface{}) (interface{}, error) {
+	if schema.Nullable && instance == nil {
+		return instance, nil
+	}
+
+	switch schema.Form() {
+	case FormRef:
+		if state.Refs.loops(*schema.Ref, state.Depth) {
+			return nil, ErrUnproductiveRefCycle
+		}
+
+		state.Refs = append(state.Refs, refFrame{Name: *schema.Ref, InstanceDepth: state.Depth})
+		out, err := applyDefaults(state, state.Root.Definitions[*schema.Ref], instance)
+		state.Refs = state.Refs[:len(state.Refs)-1]
+
+		return out, err
+	case FormElements:
+		arr, ok := instance.([]interface{})
+		if !ok {
+			return instance, nil
+		}
+
+		out := make([]interface{}, len(arr))
+		for i, subInstance := range arr {
+			state.Depth++
+			v, err := applyDefaults(state, *schema.Elements, subInstance)
+			state.Depth--
+
+			if err != nil {
+				return nil, err
+			}
+
+			out[i] = v
+		}
+
+		return out, nil
+	case FormProperties:
+		obj, ok := instance.(map[string]interface{})
+		if !ok {
+			return instance, nil
+		}
+
+		out := make(map[string]interface{}, len(obj))
+		for key, subInstance := range obj {
+			out[key] = subInstance
+		}
+
+		for _, properties := range []map[string]Schema{schema.Properties, schema.OptionalProperties} {
+			for key, subSchema := range properties {
+				subInstance, ok := obj[key]
+				if !ok {
+					continue
+				}
+
+				state.Depth++
+				v, err := applyDefaults(state, subSchema, subInstance)
+				state.Depth--
+
+				if err != nil {
+					return nil, err
+				}
+
+				out[key] = v
+			}
+		}
+
+		for key, subSchema := range schema.OptionalProperties {
+			if _, ok := obj[key]; ok {
+				continue
+			}
+
+			if v, ok := subSchema.Default(); ok {
+				out[key] = copyJSON(v)
+			}
+		}
+
+		return out, nil
+	case FormValues:
+		obj, ok := instance.(map[string]interface{})
+		if !ok {
+			return instance, nil
+		}
+
+		out := make(map[string]interface{}, len(obj))
+		for key, subInstance := range obj {
+			state.Depth++
+			v, err := applyDefaults(state, *schema.Values, subInstance)
+			state.Depth--
+
+			if err != nil {
+				return nil, err
+			}
+
+			out[key] = v
+		}
+
+		return out, nil
+	case FormDiscriminator:
+		obj, ok := instance.(map[string]interface{})
+		if !ok {
+			return instance, nil
+		}
+
+		tag, ok := obj[schema.Discriminator].(string)
+		if !ok {
+			return instance, nil
+		}
+
+		mapping, ok := schema.Mapping[tag]
+		if !ok {
+			return instance, nil
+		}
+
+		return applyDefaults(state, mapping, instance)
+	default:
+		return instance, nil
+	}
+}
+
+// copyJSON returns a deep copy of v, a value made up of the types that
+// encoding/json unmarshals into an interface{}.
+func copyJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = copyJSON(value)
+		}
+
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = copyJSON(value)
+		}
+
+		return out
+	default:
+		return v
+	}
+}
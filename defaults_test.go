@@ -0,0 +1,172 @@
+package jtd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"server": {
+				"properties": { "host": { "type": "string" } },
+				"optionalProperties": {
+					"port": { "type": "uint16", "metadata": { "default": 8080 } },
+					"tags": { "elements": { "type": "string" }, "metadata": { "default": ["a"] } }
+				}
+			}
+		},
+		"properties": {
+			"servers": { "elements": { "ref": "server" } },
+			"backends": { "values": { "ref": "server" } },
+			"auth": {
+				"discriminator": "kind",
+				"mapping": {
+					"token": {
+						"optionalProperties": {
+							"header": { "type": "string", "metadata": { "default": "Authorization" } }
+						}
+					}
+				}
+			}
+		},
+		"optionalProperties": {
+			"debug": { "type": "boolean", "metadata": { "default": false } },
+			"nothing": { "type": "string", "nullable": true }
+		}
+	}`), &schema))
+	assert.NoError(t, schema.Validate())
+
+	var instance interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"servers": [{ "host": "a", "port": 1 }, { "host": "b" }, "not a server"],
+		"backends": { "x": { "host": "c", "tags": [] } },
+		"auth": { "kind": "token" }
+	}`), &instance))
+
+	out, err := jtd.ApplyDefaults(schema, instance)
+	assert.NoError(t, err)
+
+	var expected interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"servers": [
+			{ "host": "a", "port": 1, "tags": ["a"] },
+			{ "host": "b", "port": 8080, "tags": ["a"] },
+			"not a server"
+		],
+		"backends": { "x": { "host": "c", "port": 8080, "tags": [] } },
+		"auth": { "kind": "token", "header": "Authorization" },
+		"debug": false
+	}`), &expected))
+	assert.Equal(t, expected, out)
+
+	// The input must not have been modified.
+	assert.NotContains(t, instance.(map[string]interface{}), "debug")
+
+	// Each filled-in default is a separate copy.
+	servers := out.(map[string]interface{})["servers"].([]interface{})
+	servers[0].(map[string]interface{})["tags"].([]interface{})[0] = "changed"
+	assert.Equal(t, "a", servers[1].(map[string]interface{})["tags"].([]interface{})[0])
+}
+
+func TestApplyDefaultsUnproductiveRefCycle(t *testing.T) {
+	loop := "loop"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{"loop": jtd.Schema{Ref: &loop}},
+		Ref:         &loop,
+	}
+
+	_, err := jtd.ApplyDefaults(schema, nil)
+	assert.Equal(t, jtd.ErrUnproductiveRefCycle, err)
+}
+
+func TestInvalidDefault(t *testing.T) {
+	money := "money"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"money": jtd.Schema{Type: jtd.TypeString},
+		},
+		OptionalProperties: map[string]jtd.Schema{
+			"price": jtd.Schema{
+				Ref:      &money,
+				Metadata: map[string]interface{}{"default": "1.00"},
+			},
+		},
+	}
+	assert.NoError(t, schema.Validate())
+
+	schema.OptionalProperties["price"].Metadata["default"] = 1.0
+	assert.Equal(t, jtd.ErrInvalidDefault, schema.Validate())
+}
+
+func TestMappingDefault(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"discriminator": "kind",
+		"mapping": {
+			"a": {
+				"properties": { "x": { "type": "string" } },
+				"metadata": { "default": { "kind": "a", "x": "foo" } }
+			},
+			"b": { "properties": {} }
+		}
+	}`), &schema))
+	assert.NoError(t, schema.Validate())
+
+	// The default of a mapping must have the tag of that mapping.
+	for _, def := range []interface{}{
+		map[string]interface{}{"x": "foo"},
+		map[string]interface{}{"kind": "b", "x": "foo"},
+		map[string]interface{}{"kind": "a", "x": 1.0},
+	} {
+		schema.Mapping["a"].Metadata["default"] = def
+		assert.Equal(t, jtd.ErrInvalidDefault, schema.Validate(), "%v", def)
+	}
+}
+
+func TestDefaultRefCycle(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"a": { "ref": "b" },
+			"b": { "ref": "a" },
+			"c": { "ref": "a", "metadata": { "default": "x" } }
+		}
+	}`), &schema))
+	assert.Equal(t, jtd.ErrUnproductiveRefCycle, schema.Validate())
+
+	// Defaults are checked only once the rest of the schema is valid, so the
+	// result doesn't depend on the order in which definitions are checked.
+	schema.Definitions["d"] = jtd.Schema{Type: "nope"}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, jtd.ErrInvalidType, schema.Validate())
+	}
+}
+
+func ExampleApplyDefaults() {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"name": jtd.Schema{Type: jtd.TypeString},
+		},
+		OptionalProperties: map[string]jtd.Schema{
+			"retries": jtd.Schema{
+				Type:     jtd.TypeUint8,
+				Metadata: map[string]interface{}{"default": 3.0},
+			},
+		},
+	}
+
+	instance := map[string]interface{}{"name": "job"}
+
+	out, _ := jtd.ApplyDefaults(schema, instance)
+	fmt.Println(out)
+	fmt.Println(jtd.Validate(schema, out))
+	// Output:
+	// map[name:job retries:3]
+	// [] <nil>
+}
@@ -0,0 +1,230 @@
+// Package docgen generates reference documentation, in Markdown or HTML, from
+// JSON Typedef schemas.
+//
+// The documentation has one section for the root schema (unless it is of the
+// empty form, as is usual for libraries of definitions) and one per
+// definition. Each section shows the "description" metadata of its schema,
+// its properties, enum values, or discriminator variants, and an example
+// instance as produced by Example.
+package docgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// document is the format-independent contents of the generated documentation.
+type document struct {
+	Title    string
+	Sections []section
+}
+
+type section struct {
+	ID          string
+	Name        string
+	Description string
+
+	// Type is set for schemas that are not of the properties, enum, or
+	// discriminator form.
+	Type *typeRef
+
+	Properties []property
+
+	EnumValues []string
+
+	Discriminator string
+	Variants      []variant
+
+	Example string
+}
+
+type property struct {
+	Name        string
+	Type        typeRef
+	Required    bool
+	Nullable    bool
+	Description string
+}
+
+type variant struct {
+	ID          string
+	Tag         string
+	Description string
+	Properties  []property
+	Example     string
+}
+
+// typeRef is a short description of a schema, like "array of string". If Link
+// is set, then Name is the name of a definition, and Link is the ID of its
+// section.
+type typeRef struct {
+	Prefix string
+	Name   string
+	Link   string
+}
+
+// documenter builds a document for root.
+type documenter struct {
+	root jtd.Schema
+
+	// definitionIDs holds the ID of the section of each definition, and usedIDs
+	// the IDs that have been given out, so that each one is unique.
+	definitionIDs map[string]string
+	usedIDs       map[string]bool
+}
+
+func newDocument(schema jtd.Schema, title string) document {
+	d := documenter{
+		root:          schema,
+		definitionIDs: map[string]string{},
+		usedIDs:       map[string]bool{"root": true},
+	}
+
+	for _, name := range schemautil.SortedKeys(schema.Definitions) {
+		d.definitionIDs[name] = d.uniqueID("definitions-" + anchor(name))
+	}
+
+	doc := document{Title: title}
+	if schema.Form() != jtd.FormEmpty || len(schema.Definitions) == 0 {
+		doc.Sections = append(doc.Sections, d.section("root", "Root", schema))
+	}
+
+	for _, name := range schemautil.SortedKeys(schema.Definitions) {
+		doc.Sections = append(doc.Sections, d.section(d.definitionIDs[name], name, schema.Definitions[name]))
+	}
+
+	return doc
+}
+
+// uniqueID returns id, or if it is already used, id with the smallest numeric
+// suffix that makes it unique. Distinct names can have the same anchor, such as
+// "a b" and "a-b".
+func (d *documenter) uniqueID(id string) string {
+	unique := id
+	for i := 2; d.usedIDs[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", id, i)
+	}
+
+	d.usedIDs[unique] = true
+	return unique
+}
+
+func (d *documenter) section(id, name string, s jtd.Schema) section {
+	sec := section{ID: id, Name: name, Description: s.Description()}
+
+	switch s.Form() {
+	case jtd.FormProperties:
+		sec.Properties = d.properties("", s)
+	case jtd.FormEnum:
+		sec.EnumValues = s.Enum
+	case jtd.FormDiscriminator:
+		sec.Discriminator = s.Discriminator
+		for _, tag := range schemautil.SortedKeys(s.Mapping) {
+			// The example may be the "example" metadata of the mapping, which must
+			// not be modified, so the tag is added to a copy of it.
+			var example map[string]interface{}
+			if e, ok := Example(d.root, s.Mapping[tag]).(map[string]interface{}); ok {
+				example = make(map[string]interface{}, len(e)+1)
+				for k, v := range e {
+					example[k] = v
+				}
+
+				example[s.Discriminator] = tag
+			}
+
+			sec.Variants = append(sec.Variants, variant{
+				ID:          d.uniqueID(id + "-" + anchor(tag)),
+				Tag:         tag,
+				Description: s.Mapping[tag].Description(),
+				Properties:  d.properties("", s.Mapping[tag]),
+				Example:     marshalExample(example),
+			})
+		}
+
+		// Each variant has its own example instead.
+		return sec
+	default:
+		t := d.typeRef(s)
+		sec.Type = &t
+	}
+
+	sec.Example = marshalExample(Example(d.root, s))
+	return sec
+}
+
+func marshalExample(v interface{}) string {
+	// Examples are made only of JSON values, so they always marshal.
+	b, _ := json.MarshalIndent(v, "", "  ")
+	return string(b)
+}
+
+// properties returns the properties of s, a schema of the properties form.
+// Properties of nested schemas of the properties form are listed as well, with
+// names like "parent.child" or "parent[].child".
+func (d *documenter) properties(prefix string, s jtd.Schema) []property {
+	var out []property
+	for _, required := range []bool{true, false} {
+		schemas := s.Properties
+		if !required {
+			schemas = s.OptionalProperties
+		}
+
+		for _, name := range schemautil.SortedKeys(schemas) {
+			p := schemas[name]
+			out = append(out, property{
+				Name:        prefix + name,
+				Type:        d.typeRef(p),
+				Required:    required,
+				Nullable:    p.Nullable,
+				Description: p.Description(),
+			})
+
+			nested, nestedPrefix := p, prefix+name
+			for nested.Form() == jtd.FormElements {
+				nested, nestedPrefix = *nested.Elements, nestedPrefix+"[]"
+			}
+
+			if nested.Form() == jtd.FormProperties {
+				out = append(out, d.properties(nestedPrefix+".", nested)...)
+			}
+		}
+	}
+
+	return out
+}
+
+func (d *documenter) typeRef(s jtd.Schema) typeRef {
+	switch s.Form() {
+	case jtd.FormRef:
+		return typeRef{Name: *s.Ref, Link: d.definitionIDs[*s.Ref]}
+	case jtd.FormType:
+		return typeRef{Name: string(s.Type)}
+	case jtd.FormEnum:
+		return typeRef{Name: "enum (" + strings.Join(s.Enum, ", ") + ")"}
+	case jtd.FormElements:
+		t := d.typeRef(*s.Elements)
+		t.Prefix = "array of " + t.Prefix
+		return t
+	case jtd.FormProperties:
+		return typeRef{Name: "object"}
+	case jtd.FormValues:
+		t := d.typeRef(*s.Values)
+		t.Prefix = "map of " + t.Prefix
+		return t
+	case jtd.FormDiscriminator:
+		return typeRef{Name: "object"}
+	default:
+		return typeRef{Name: "any"}
+	}
+}
+
+var nonAnchor = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func anchor(s string) string {
+	return strings.Trim(nonAnchor.ReplaceAllString(s, "-"), "-")
+}
@@ -0,0 +1,195 @@
+package docgen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/docgen"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `{
+	"definitions": {
+		"event": {
+			"discriminator": "type",
+			"mapping": {
+				"created": {
+					"metadata": { "description": "Something was created." },
+					"properties": { "id": { "type": "string" } }
+				},
+				"deleted": {
+					"properties": {},
+					"optionalProperties": { "reason": { "type": "string", "nullable": true } }
+				}
+			}
+		},
+		"money": {
+			"metadata": { "description": "An amount | of money." },
+			"properties": {
+				"amount": { "type": "string", "metadata": { "example": "1.99" } },
+				"currency": { "enum": ["EUR", "USD"] }
+			}
+		},
+		"tree": {
+			"properties": {
+				"children": { "elements": { "ref": "tree" } }
+			}
+		}
+	},
+	"properties": {
+		"total": { "ref": "money" },
+		"items": {
+			"elements": {
+				"properties": { "sku": { "type": "string" } }
+			}
+		}
+	}
+}`
+
+func loadTestSchema(t *testing.T) jtd.Schema {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(testSchema), &schema))
+	assert.NoError(t, schema.Validate())
+	return schema
+}
+
+func TestMarkdown(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, docgen.Markdown(&b, loadTestSchema(t), "Orders"))
+
+	out := b.String()
+	assert.Contains(t, out, "# Orders\n")
+	assert.Contains(t, out, "<a id=\"definitions-money\"></a>\n\n## money\n\nAn amount | of money.\n")
+	assert.Contains(t, out, "| `items[].sku` | `string` | yes | no |  |\n")
+	assert.Contains(t, out, "| `total` | [`money`](#definitions-money) | yes | no |  |\n")
+	assert.Contains(t, out, "| `reason` | `string` | no | yes |  |\n")
+	assert.Contains(t, out, "### type = `created`\n\nSomething was created.\n")
+	assert.Contains(t, out, "| `currency` | `enum (EUR, USD)` | yes | no |  |\n")
+	assert.Contains(t, out, "\"amount\": \"1.99\"")
+}
+
+func TestHTML(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, docgen.HTML(&b, loadTestSchema(t), "Orders & Co"))
+
+	out := b.String()
+	assert.Contains(t, out, "<title>Orders &amp; Co</title>")
+	assert.Contains(t, out, "<section id=\"definitions-tree\">")
+	assert.Contains(t, out, "<td><a href=\"#definitions-money\"><code>money</code></a></td>")
+	assert.Contains(t, out, "<input type=\"radio\" name=\"definitions-event\" id=\"definitions-event-created\" checked>")
+	assert.Contains(t, out, "<input type=\"radio\" name=\"definitions-event\" id=\"definitions-event-deleted\">")
+}
+
+func TestUniqueAnchors(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"a b": { "type": "string" },
+			"a-b": { "type": "string" },
+			"a": {
+				"discriminator": "kind",
+				"mapping": {
+					"b": {
+						"properties": {},
+						"metadata": { "example": { "x": 1 } }
+					}
+				}
+			}
+		},
+		"properties": {
+			"x": { "ref": "a b" },
+			"y": { "ref": "a-b" }
+		}
+	}`), &schema))
+
+	var b bytes.Buffer
+	assert.NoError(t, docgen.Markdown(&b, schema, "Anchors"))
+
+	out := b.String()
+	assert.Contains(t, out, "<a id=\"definitions-a-b\"></a>\n\n## a b\n")
+	assert.Contains(t, out, "<a id=\"definitions-a-b-2\"></a>\n\n## a-b\n")
+	assert.Contains(t, out, "[`a b`](#definitions-a-b)")
+	assert.Contains(t, out, "[`a-b`](#definitions-a-b-2)")
+	assert.Contains(t, out, "\"kind\": \"b\"")
+
+	// The variant of "a" would have had the same anchor as "a b".
+	var html bytes.Buffer
+	assert.NoError(t, docgen.HTML(&html, schema, "Anchors"))
+	assert.Contains(t, html.String(), "id=\"definitions-a-b-3\" checked>")
+
+	// Adding the tag to the example must not modify the schema.
+	assert.Equal(t, map[string]interface{}{"x": 1.0}, schema.Definitions["a"].Mapping["b"].Metadata["example"])
+}
+
+func TestExample(t *testing.T) {
+	schema := loadTestSchema(t)
+
+	for name, def := range schema.Definitions {
+		if def.Form() == jtd.FormDiscriminator {
+			continue
+		}
+
+		name := name
+		root := schema
+		root.Properties = nil
+		root.Ref = &name
+
+		errs, err := jtd.Validate(root, docgen.Example(schema, def))
+		assert.NoError(t, err)
+		assert.Empty(t, errs, name)
+	}
+
+	errs, err := jtd.Validate(schema, docgen.Example(schema, schema))
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+}
+
+func ExampleMarkdown() {
+	schema := jtd.Schema{
+		Metadata: map[string]interface{}{"description": "A user."},
+		Properties: map[string]jtd.Schema{
+			"name": jtd.Schema{Type: jtd.TypeString},
+		},
+	}
+
+	docgen.Markdown(os.Stdout, schema, "Users")
+	// Output:
+	// # Users
+	//
+	// <a id="root"></a>
+	//
+	// ## Root
+	//
+	// A user.
+	//
+	// | Property | Type | Required | Nullable | Description |
+	// | -------- | ---- | -------- | -------- | ----------- |
+	// | `name` | `string` | yes | no |  |
+	//
+	// Example:
+	//
+	// ```json
+	// {
+	//   "name": "string"
+	// }
+	// ```
+}
+
+func ExampleExample() {
+	schema := jtd.Schema{
+		Elements: &jtd.Schema{
+			Properties: map[string]jtd.Schema{
+				"id":      jtd.Schema{Type: jtd.TypeUint32},
+				"created": jtd.Schema{Type: jtd.TypeTimestamp},
+			},
+		},
+	}
+
+	fmt.Println(docgen.Example(schema, schema))
+	// Output:
+	// [map[created:1970-01-01T00:00:00Z id:0]]
+}
@@ -0,0 +1,113 @@
+package docgen
+
+import (
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// Example returns an instance of s, a schema within root, suitable for
+// illustrating documentation.
+//
+// If s has an "example" in its metadata, that is returned as-is. Otherwise, an
+// instance is synthesized from s: every property is filled in, arrays and maps
+// have one entry, enums and discriminators take their first value, and
+// nullable schemas are given a non-null value. Recursive schemas are cut short
+// with empty arrays and maps, or nulls, wherever possible.
+func Example(root, s jtd.Schema) interface{} {
+	v, _ := example(root, s, map[string]bool{})
+	return v
+}
+
+// example returns an example of s. If it's not possible to produce an example
+// without recursing forever, it returns false. expanding is the set of
+// definitions currently being expanded.
+func example(root, s jtd.Schema, expanding map[string]bool) (interface{}, bool) {
+	if v, ok := s.Example(); ok {
+		return v, true
+	}
+
+	v, ok := formExample(root, s, expanding)
+	if !ok && s.Nullable {
+		return nil, true
+	}
+
+	return v, ok
+}
+
+func formExample(root, s jtd.Schema, expanding map[string]bool) (interface{}, bool) {
+	switch s.Form() {
+	case jtd.FormRef:
+		if expanding[*s.Ref] {
+			return nil, false
+		}
+
+		expanding[*s.Ref] = true
+		defer delete(expanding, *s.Ref)
+
+		return example(root, root.Definitions[*s.Ref], expanding)
+	case jtd.FormType:
+		switch s.Type {
+		case jtd.TypeBoolean:
+			return true, true
+		case jtd.TypeString:
+			return "string", true
+		case jtd.TypeTimestamp:
+			return "1970-01-01T00:00:00Z", true
+		default:
+			return 0, true
+		}
+	case jtd.FormEnum:
+		return s.Enum[0], true
+	case jtd.FormElements:
+		if v, ok := example(root, *s.Elements, expanding); ok {
+			return []interface{}{v}, true
+		}
+
+		return []interface{}{}, true
+	case jtd.FormProperties:
+		out := map[string]interface{}{}
+		if !propertiesExample(root, s, expanding, out) {
+			return nil, false
+		}
+
+		return out, true
+	case jtd.FormValues:
+		if v, ok := example(root, *s.Values, expanding); ok {
+			return map[string]interface{}{"key": v}, true
+		}
+
+		return map[string]interface{}{}, true
+	case jtd.FormDiscriminator:
+		for _, tag := range schemautil.SortedKeys(s.Mapping) {
+			out := map[string]interface{}{s.Discriminator: tag}
+			if propertiesExample(root, s.Mapping[tag], expanding, out) {
+				return out, true
+			}
+		}
+
+		return nil, false
+	default:
+		return map[string]interface{}{}, true
+	}
+}
+
+// propertiesExample adds examples of the properties of s to out. It returns
+// false if it could not produce an example for a required property.
+func propertiesExample(root, s jtd.Schema, expanding map[string]bool, out map[string]interface{}) bool {
+	for name, property := range s.Properties {
+		v, ok := example(root, property, expanding)
+		if !ok {
+			return false
+		}
+
+		out[name] = v
+	}
+
+	for name, property := range s.OptionalProperties {
+		if v, ok := example(root, property, expanding); ok {
+			out[name] = v
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,92 @@
+package docgen
+
+import (
+	"html/template"
+	"io"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+)
+
+// HTML writes documentation for schema to w as a standalone HTML page, titled
+// title.
+//
+// Discriminator variants are rendered as tabs. The page uses no JavaScript,
+// and all of its styles are inline.
+func HTML(w io.Writer, schema jtd.Schema, title string) error {
+	return htmlTemplate.Execute(w, newDocument(schema, title))
+}
+
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+pre { background: #f6f8fa; padding: 1em; overflow: auto; }
+.tabs { display: flex; flex-wrap: wrap; margin: 1em 0; }
+.tabs > input { display: none; }
+.tabs > label { order: 1; padding: 0.5em 1em; border: 1px solid #ccc; border-bottom: none; cursor: pointer; }
+.tabs > .tab { order: 2; display: none; width: 100%; border-top: 1px solid #ccc; }
+.tabs > input:checked + label { background: #f6f8fa; font-weight: bold; }
+.tabs > input:checked + label + .tab { display: block; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+{{- range $section := .Sections }}
+<section id="{{ .ID }}">
+<h2>{{ .Name }}</h2>
+{{- with .Description }}
+<p>{{ . }}</p>
+{{- end }}
+{{- with .Type }}
+<p>Type: {{ template "type" . }}</p>
+{{- end }}
+{{- with .Properties }}
+{{ template "properties" . }}
+{{- end }}
+{{- with .EnumValues }}
+<p>One of:</p>
+<ul>
+{{- range . }}
+<li><code>{{ . }}</code></li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Discriminator }}
+<p>Discriminated by the <code>{{ .Discriminator }}</code> property, which is one of:</p>
+<div class="tabs">
+{{- range $i, $variant := .Variants }}
+<input type="radio" name="{{ $section.ID }}" id="{{ .ID }}"{{ if eq $i 0 }} checked{{ end }}>
+<label for="{{ .ID }}"><code>{{ .Tag }}</code></label>
+<div class="tab">
+{{- with .Description }}
+<p>{{ . }}</p>
+{{- end }}
+{{- with .Properties }}
+{{ template "properties" . }}
+{{- end }}
+{{ template "example" .Example }}
+</div>
+{{- end }}
+</div>
+{{- else }}
+{{ template "example" .Example }}
+{{- end }}
+</section>
+{{- end }}
+</body>
+</html>
+{{ define "type" }}{{ .Prefix }}{{ if .Link }}<a href="#{{ .Link }}"><code>{{ .Name }}</code></a>{{ else }}<code>{{ .Name }}</code>{{ end }}{{ end }}
+{{- define "example" }}<p>Example:</p>
+<pre><code>{{ . }}</code></pre>{{ end }}
+{{- define "properties" }}<table>
+<tr><th>Property</th><th>Type</th><th>Required</th><th>Nullable</th><th>Description</th></tr>
+{{- range . }}
+<tr><td><code>{{ .Name }}</code></td><td>{{ template "type" .Type }}</td><td>{{ if .Required }}yes{{ else }}no{{ end }}</td><td>{{ if .Nullable }}yes{{ else }}no{{ end }}</td><td>{{ .Description }}</td></tr>
+{{- end }}
+</table>{{ end }}
+`))
@@ -0,0 +1,87 @@
+package docgen
+
+import (
+	"io"
+	"strings"
+	"text/template"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+)
+
+// Markdown writes documentation for schema to w as Markdown, under a top-level
+// heading of title.
+//
+// Each section is preceded by an HTML anchor, so that refs can link to the
+// definitions they point to. Discriminator variants are rendered as
+// subsections.
+func Markdown(w io.Writer, schema jtd.Schema, title string) error {
+	return markdownTemplate.Execute(w, newDocument(schema, title))
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(template.FuncMap{
+	"cell": markdownCell,
+	"type": markdownType,
+}).Parse(`# {{ .Title }}
+{{ range $section := .Sections }}
+<a id="{{ .ID }}"></a>
+
+## {{ .Name }}
+{{ with .Description }}
+{{ . }}
+{{ end }}
+{{- with .Type }}
+Type: {{ type . }}
+{{ end }}
+{{- with .Properties }}
+{{ template "properties" . }}
+{{- end }}
+{{- with .EnumValues }}
+One of:
+{{ range . }}
+* ` + "`{{ . }}`" + `
+{{- end }}
+{{ end }}
+{{- if .Discriminator }}
+Discriminated by the ` + "`{{ .Discriminator }}`" + ` property, which is one of:
+{{ range .Variants }}
+<a id="{{ .ID }}"></a>
+
+### {{ $section.Discriminator }} = ` + "`{{ .Tag }}`" + `
+{{ with .Description }}
+{{ . }}
+{{ end }}
+{{- with .Properties }}
+{{ template "properties" . }}
+{{- end }}
+{{ template "example" .Example }}
+{{- end }}
+{{- else }}
+{{ template "example" .Example }}
+{{- end }}
+{{- end }}
+{{- define "example" -}}
+Example:
+
+` + "```json" + `
+{{ . }}
+` + "```" + `
+{{ end }}
+{{- define "properties" -}}
+| Property | Type | Required | Nullable | Description |
+| -------- | ---- | -------- | -------- | ----------- |
+{{ range . -}}
+| ` + "`{{ cell .Name }}`" + ` | {{ type .Type }} | {{ if .Required }}yes{{ else }}no{{ end }} | {{ if .Nullable }}yes{{ else }}no{{ end }} | {{ cell .Description }} |
+{{ end -}}
+{{ end }}`))
+
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ").Replace(s)
+}
+
+func markdownType(t typeRef) string {
+	if t.Link != "" {
+		return markdownCell(t.Prefix + "[`" + t.Name + "`](#" + t.Link + ")")
+	}
+
+	return markdownCell(t.Prefix + "`" + t.Name + "`")
+}
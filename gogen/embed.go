@@ -0,0 +1,290 @@
+package gogen
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// ErrInvalidName indicates that a name given to Embed or GenerateValidator is
+// not an exported Go identifier, or is used more than once.
+var ErrInvalidName = errors.New("gogen: invalid name")
+
+// EmbeddedSchema is a schema for Embed to generate code for.
+type EmbeddedSchema struct {
+	// Name is the exported Go identifier that the names of the generated
+	// variables start with.
+	Name string
+
+	// Source is where the schema comes from, such as a file name. It is only
+	// used in comments.
+	Source string
+
+	// Schema is the schema itself, which must be a valid root schema.
+	Schema jtd.Schema
+}
+
+// typeNames are the names of the constants of each jtd.Type.
+var typeNames = map[jtd.Type]string{
+	jtd.TypeBoolean:   "TypeBoolean",
+	jtd.TypeFloat32:   "TypeFloat32",
+	jtd.TypeFloat64:   "TypeFloat64",
+	jtd.TypeInt8:      "TypeInt8",
+	jtd.TypeUint8:     "TypeUint8",
+	jtd.TypeInt16:     "TypeInt16",
+	jtd.TypeUint16:    "TypeUint16",
+	jtd.TypeInt32:     "TypeInt32",
+	jtd.TypeUint32:    "TypeUint32",
+	jtd.TypeString:    "TypeString",
+	jtd.TypeTimestamp: "TypeTimestamp",
+}
+
+// Embed writes a Go file of package pkg to w that declares, for each of
+// schemas, two variables:
+//
+//	// NameSchema is the schema itself, as a jtd.Schema literal.
+//	var NameSchema = jtd.Schema{...}
+//
+//	// NameValidator is NameSchema, compiled.
+//	var NameValidator = jtd.MustCompile(NameSchema)
+//
+// Embed returns an error if a schema is not valid according to
+// Schema.Validate, or if its metadata contains values other than the ones
+// encoding/json unmarshals into an interface{}.
+func Embed(w io.Writer, pkg string, schemas []EmbeddedSchema) error {
+	g := embedder{}
+	fmt.Fprintf(&g.b, "// Code generated by jtd embed. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&g.b, "package %s\n\n", pkg)
+	fmt.Fprintf(&g.b, "import jtd %q\n", "github.com/jsontypedef/json-typedef-go")
+
+	names := map[string]bool{}
+	for _, s := range schemas {
+		if !token.IsIdentifier(s.Name) || !token.IsExported(s.Name) || names[s.Name] {
+			return fmt.Errorf("%w: %q", ErrInvalidName, s.Name)
+		}
+
+		names[s.Name] = true
+		if err := s.Schema.Validate(); err != nil {
+			return fmt.Errorf("gogen: %s: %w", s.Source, err)
+		}
+
+		if err := g.embed(s); err != nil {
+			return fmt.Errorf("gogen: %s: %w", s.Source, err)
+		}
+	}
+
+	out, err := format.Source([]byte(g.b.String()))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+type embedder struct {
+	b strings.Builder
+
+	// refs are the values of the refs of the schema being embedded, which are
+	// declared in an array so that the literal can point into it. refIndices
+	// maps each value to its index in refs.
+	refs       []string
+	refIndices map[string]int
+	refsName   string
+}
+
+func (g *embedder) embed(s EmbeddedSchema) error {
+	g.refs = nil
+	g.refIndices = map[string]int{}
+	g.refsName = "refs" + s.Name
+
+	var literal strings.Builder
+	if err := g.schema(&literal, s.Schema); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(&g.b, "\n// %sSchema is the JSON Typedef schema in %s.\n", s.Name, s.Source)
+	fmt.Fprintf(&g.b, "var %sSchema = jtd.Schema%s\n", s.Name, literal.String())
+	fmt.Fprintf(&g.b, "\n// %sValidator is %sSchema, compiled.\n", s.Name, s.Name)
+	fmt.Fprintf(&g.b, "var %sValidator = jtd.MustCompile(%sSchema)\n", s.Name, s.Name)
+
+	if len(g.refs) > 0 {
+		fmt.Fprintf(&g.b, "\n// %s are the refs of %sSchema.\n", g.refsName, s.Name)
+		fmt.Fprintf(&g.b, "var %s = [...]string{", g.refsName)
+		for _, ref := range g.refs {
+			fmt.Fprintf(&g.b, "%q, ", ref)
+		}
+
+		fmt.Fprintf(&g.b, "}\n")
+	}
+
+	return nil
+}
+
+// schema writes the body of a jtd.Schema literal for s to b, starting at the
+// opening brace.
+func (g *embedder) schema(b *strings.Builder, s jtd.Schema) error {
+	b.WriteString("{\n")
+
+	if s.Definitions != nil {
+		if err := g.schemaMap(b, "Definitions", s.Definitions); err != nil {
+			return err
+		}
+	}
+
+	if s.Metadata != nil {
+		b.WriteString("Metadata: ")
+		if err := writeValue(b, s.Metadata); err != nil {
+			return err
+		}
+
+		b.WriteString(",\n")
+	}
+
+	if s.Nullable {
+		b.WriteString("Nullable: true,\n")
+	}
+
+	if s.Ref != nil {
+		i, ok := g.refIndices[*s.Ref]
+		if !ok {
+			i = len(g.refs)
+			g.refIndices[*s.Ref] = i
+			g.refs = append(g.refs, *s.Ref)
+		}
+
+		fmt.Fprintf(b, "Ref: &%s[%d],\n", g.refsName, i)
+	}
+
+	if s.Type != "" {
+		fmt.Fprintf(b, "Type: jtd.%s,\n", typeNames[s.Type])
+	}
+
+	if s.Enum != nil {
+		b.WriteString("Enum: []string{")
+		for _, v := range s.Enum {
+			fmt.Fprintf(b, "%q, ", v)
+		}
+
+		b.WriteString("},\n")
+	}
+
+	if s.Elements != nil {
+		b.WriteString("Elements: &jtd.Schema")
+		if err := g.schema(b, *s.Elements); err != nil {
+			return err
+		}
+
+		b.WriteString(",\n")
+	}
+
+	if s.Properties != nil {
+		if err := g.schemaMap(b, "Properties", s.Properties); err != nil {
+			return err
+		}
+	}
+
+	if s.OptionalProperties != nil {
+		if err := g.schemaMap(b, "OptionalProperties", s.OptionalProperties); err != nil {
+			return err
+		}
+	}
+
+	if s.AdditionalProperties {
+		b.WriteString("AdditionalProperties: true,\n")
+	}
+
+	if s.Values != nil {
+		b.WriteString("Values: &jtd.Schema")
+		if err := g.schema(b, *s.Values); err != nil {
+			return err
+		}
+
+		b.WriteString(",\n")
+	}
+
+	if s.Discriminator != "" {
+		fmt.Fprintf(b, "Discriminator: %q,\n", s.Discriminator)
+	}
+
+	if s.Mapping != nil {
+		if err := g.schemaMap(b, "Mapping", s.Mapping); err != nil {
+			return err
+		}
+	}
+
+	b.WriteString("}")
+	return nil
+}
+
+func (g *embedder) schemaMap(b *strings.Builder, field string, m map[string]jtd.Schema) error {
+	fmt.Fprintf(b, "%s: map[string]jtd.Schema{\n", field)
+	for _, name := range schemautil.SortedKeys(m) {
+		fmt.Fprintf(b, "%q: ", name)
+		if err := g.schema(b, m[name]); err != nil {
+			return err
+		}
+
+		b.WriteString(",\n")
+	}
+
+	b.WriteString("},\n")
+	return nil
+}
+
+// writeValue writes a Go expression for v, a value made up of the types that
+// encoding/json unmarshals into an interface{}, to b.
+func writeValue(b *strings.Builder, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("nil")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case float64:
+		fmt.Fprintf(b, "float64(%s)", strconv.FormatFloat(v, 'g', -1, 64))
+	case string:
+		b.WriteString(strconv.Quote(v))
+	case []interface{}:
+		b.WriteString("[]interface{}{")
+		for _, elem := range v {
+			if err := writeValue(b, elem); err != nil {
+				return err
+			}
+
+			b.WriteString(", ")
+		}
+
+		b.WriteString("}")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		b.WriteString("map[string]interface{}{\n")
+		for _, k := range keys {
+			fmt.Fprintf(b, "%q: ", k)
+			if err := writeValue(b, v[k]); err != nil {
+				return err
+			}
+
+			b.WriteString(",\n")
+		}
+
+		b.WriteString("}")
+	default:
+		return fmt.Errorf("unsupported metadata value of type %T", v)
+	}
+
+	return nil
+}
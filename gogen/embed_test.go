@@ -0,0 +1,104 @@
+package gogen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/gogen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbed(t *testing.T) {
+	// The embedtest package holds the output of "jtd embed" for its schemas,
+	// and tests that it builds and matches them. Make sure that it's up to date.
+	dir := filepath.Join("internal", "embedtest")
+
+	var schemas []gogen.EmbeddedSchema
+	for _, name := range []string{"user", "user-event"} {
+		source := name + ".jtd.json"
+		data, err := ioutil.ReadFile(filepath.Join(dir, source))
+		assert.NoError(t, err)
+
+		var schema jtd.Schema
+		assert.NoError(t, json.Unmarshal(data, &schema))
+		schemas = append(schemas, gogen.EmbeddedSchema{
+			Name:   gogen.ExportedName(name),
+			Source: source,
+			Schema: schema,
+		})
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, gogen.Embed(&b, "embedtest", schemas))
+
+	expected, err := ioutil.ReadFile(filepath.Join(dir, "schemas_jtd.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), b.String(), "run go generate ./gogen/...")
+}
+
+func TestEmbedErrors(t *testing.T) {
+	foo := "foo"
+
+	testCases := []struct {
+		name    string
+		schemas []gogen.EmbeddedSchema
+		err     error
+	}{
+		{
+			name:    "unexported name",
+			schemas: []gogen.EmbeddedSchema{{Name: "user"}},
+			err:     gogen.ErrInvalidName,
+		},
+		{
+			name:    "not an identifier",
+			schemas: []gogen.EmbeddedSchema{{Name: "User-Event"}},
+			err:     gogen.ErrInvalidName,
+		},
+		{
+			name:    "repeated name",
+			schemas: []gogen.EmbeddedSchema{{Name: "User"}, {Name: "User"}},
+			err:     gogen.ErrInvalidName,
+		},
+		{
+			name:    "invalid schema",
+			schemas: []gogen.EmbeddedSchema{{Name: "User", Schema: jtd.Schema{Ref: &foo}}},
+			err:     jtd.ErrNoSuchDefinition,
+		},
+		{
+			name: "unsupported metadata",
+			schemas: []gogen.EmbeddedSchema{{Name: "User", Schema: jtd.Schema{
+				Metadata: map[string]interface{}{"count": 1},
+			}}},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := gogen.Embed(&b, "foo", tt.schemas)
+			assert.Error(t, err)
+
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err), err)
+			}
+		})
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"user":          "User",
+		"user-event.v2": "UserEventV2",
+		"common_money":  "CommonMoney",
+		"2fa":           "X2fa",
+		"":              "X",
+		"élan":          "Élan",
+	} {
+		assert.Equal(t, expected, gogen.ExportedName(name), name)
+	}
+}
@@ -0,0 +1,52 @@
+// Package gogen generates Go code from JSON Typedef schemas.
+//
+// Embed generates Go files that carry schemas with them, as jtd.Schema
+// literals, along with their compiled form. It is meant to be run with go
+// generate, through "jtd embed":
+//
+//	//go:generate go run github.com/jsontypedef/json-typedef-go/cmd/jtd embed -o schemas_jtd.go user.jtd.json
+//
+// Because Embed refuses to generate code for invalid schemas, a schema that is
+// not valid according to Schema.Validate makes go generate fail, rather than
+// the program that uses it.
+//
+// GenerateValidator goes further, and generates a validation function that is
+// specialized to a schema, through "jtd validator":
+//
+//	//go:generate go run github.com/jsontypedef/json-typedef-go/cmd/jtd validator -o order_validator.go order.jtd.json
+//
+// Generated validators return the same errors as jtd.Validate, but don't need
+// to look at the schema at runtime, which makes them faster.
+package gogen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ExportedName returns an exported Go identifier for name, by converting it
+// to PascalCase, e.g. "user-event.v2" becomes "UserEventV2".
+func ExportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	out := b.String()
+	if out == "" || !unicode.IsUpper([]rune(out)[0]) {
+		out = "X" + out
+	}
+
+	return out
+}
@@ -0,0 +1,5 @@
+// Package embedtest holds schemas embedded by "jtd embed", to test that the
+// generated code builds and matches the schema files.
+package embedtest
+
+//go:generate go run github.com/jsontypedef/json-typedef-go/cmd/jtd embed -o schemas_jtd.go user.jtd.json user-event.jtd.json
@@ -0,0 +1,39 @@
+package embedtest_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/gogen/internal/embedtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbedded(t *testing.T) {
+	for path, embedded := range map[string]jtd.Schema{
+		"user.jtd.json":       embedtest.UserSchema,
+		"user-event.jtd.json": embedtest.UserEventSchema,
+	} {
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+
+		var schema jtd.Schema
+		assert.NoError(t, json.Unmarshal(data, &schema))
+		assert.Equal(t, schema, embedded, path)
+	}
+
+	assert.Equal(t, embedtest.UserSchema, embedtest.UserValidator.Schema())
+
+	errs, err := embedtest.UserValidator.Validate(map[string]interface{}{
+		"id":      1.0,
+		"name":    "Jane",
+		"roles":   []interface{}{"ADMIN", "OWNER"},
+		"manager": nil,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []jtd.ValidateError{{
+		InstancePath: []string{"roles", "1"},
+		SchemaPath:   []string{"definitions", "role", "enum"},
+	}}, errs)
+}
@@ -0,0 +1,80 @@
+// Code generated by jtd embed. DO NOT EDIT.
+
+package embedtest
+
+import jtd "github.com/jsontypedef/json-typedef-go"
+
+// UserSchema is the JSON Typedef schema in user.jtd.json.
+var UserSchema = jtd.Schema{
+	Definitions: map[string]jtd.Schema{
+		"role": {
+			Enum: []string{"ADMIN", "MEMBER"},
+		},
+	},
+	Metadata: map[string]interface{}{
+		"description": "A user of the service.",
+	},
+	Properties: map[string]jtd.Schema{
+		"id": {
+			Type: jtd.TypeUint32,
+		},
+		"name": {
+			Type: jtd.TypeString,
+		},
+		"roles": {
+			Elements: &jtd.Schema{
+				Ref: &refsUser[0],
+			},
+		},
+	},
+	OptionalProperties: map[string]jtd.Schema{
+		"labels": {
+			Values: &jtd.Schema{
+				Type: jtd.TypeString,
+			},
+		},
+		"manager": {
+			Nullable: true,
+			Ref:      &refsUser[0],
+		},
+	},
+}
+
+// UserValidator is UserSchema, compiled.
+var UserValidator = jtd.MustCompile(UserSchema)
+
+// refsUser are the refs of UserSchema.
+var refsUser = [...]string{"role"}
+
+// UserEventSchema is the JSON Typedef schema in user-event.jtd.json.
+var UserEventSchema = jtd.Schema{
+	Discriminator: "type",
+	Mapping: map[string]jtd.Schema{
+		"created": {
+			Properties: map[string]jtd.Schema{
+				"at": {
+					Type: jtd.TypeTimestamp,
+				},
+			},
+		},
+		"deleted": {
+			Properties: map[string]jtd.Schema{
+				"at": {
+					Type: jtd.TypeTimestamp,
+				},
+			},
+			OptionalProperties: map[string]jtd.Schema{
+				"reason": {
+					Metadata: map[string]interface{}{
+						"example": "spam",
+					},
+					Type: jtd.TypeString,
+				},
+			},
+			AdditionalProperties: true,
+		},
+	},
+}
+
+// UserEventValidator is UserEventSchema, compiled.
+var UserEventValidator = jtd.MustCompile(UserEventSchema)
@@ -0,0 +1,511 @@
+// Code generated by jtd validator. DO NOT EDIT.
+
+package validatortest
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+)
+
+// ValidateOrder validates instance against the Order schema. It returns the
+// same errors as jtd.Validate, though not necessarily in the same order.
+func ValidateOrder(instance interface{}, opts ...jtd.ValidateOption) ([]jtd.ValidateError, error) {
+	v := validatorOrder{}
+	for _, opt := range opts {
+		opt(&v.settings)
+	}
+
+	v.root(instance)
+	if v.err != nil {
+		return nil, v.err
+	}
+
+	if v.errors == nil {
+		return []jtd.ValidateError{}, nil
+	}
+
+	return v.errors, nil
+}
+
+// pathTokenOrder is a token of an instance path. Index is -1 for object keys.
+type pathTokenOrder struct {
+	key   string
+	index int
+}
+
+// validatorOrder is the state of an ongoing validation.
+type validatorOrder struct {
+	settings     jtd.ValidateSettings
+	errors       []jtd.ValidateError
+	instancePath []pathTokenOrder
+
+	// depth is the number of refs being followed.
+	depth int
+
+	// done is set when validation must stop, because MaxErrors was reached or
+	// because of err.
+	done bool
+	err  error
+}
+
+func (v *validatorOrder) pushKey(key string) {
+	v.instancePath = append(v.instancePath, pathTokenOrder{key: key, index: -1})
+}
+
+func (v *validatorOrder) pushIndex(index int) {
+	v.instancePath = append(v.instancePath, pathTokenOrder{index: index})
+}
+
+func (v *validatorOrder) pop() {
+	v.instancePath = v.instancePath[:len(v.instancePath)-1]
+}
+
+func (v *validatorOrder) fail(schemaPath ...string) {
+	instancePath := make([]string, len(v.instancePath))
+	for i, t := range v.instancePath {
+		if t.index == -1 {
+			instancePath[i] = t.key
+		} else {
+			instancePath[i] = strconv.Itoa(t.index)
+		}
+	}
+
+	if schemaPath == nil {
+		schemaPath = []string{}
+	}
+
+	v.errors = append(v.errors, jtd.ValidateError{InstancePath: instancePath, SchemaPath: schemaPath})
+	if len(v.errors) == v.settings.MaxErrors {
+		v.done = true
+	}
+}
+
+// enter is called before following a ref. It returns false if MaxDepth was
+// reached.
+func (v *validatorOrder) enter() bool {
+	if v.depth+1 == v.settings.MaxDepth {
+		v.err = jtd.ErrMaxDepthExceeded
+		v.done = true
+		return false
+	}
+
+	v.depth++
+	return true
+}
+
+// root validates instance against the root schema.
+func (v *validatorOrder) root(instance interface{}) {
+	if o1, ok := instance.(map[string]interface{}); !ok {
+		v.fail("properties")
+		if v.done {
+			return
+		}
+	} else {
+		if x2, ok := o1["id"]; ok {
+			v.pushKey("id")
+			if _, ok := x2.(string); !ok {
+				v.fail("properties", "id", "type")
+				if v.done {
+					return
+				}
+			}
+			v.pop()
+		} else {
+			v.fail("properties", "id")
+			if v.done {
+				return
+			}
+		}
+		if x3, ok := o1["items"]; ok {
+			v.pushKey("items")
+			if a4, ok := x3.([]interface{}); !ok {
+				v.fail("properties", "items", "elements")
+				if v.done {
+					return
+				}
+			} else {
+				for i5, x6 := range a4 {
+					v.pushIndex(i5)
+					v.definitionItem(x6)
+					if v.done {
+						return
+					}
+					v.pop()
+				}
+			}
+			v.pop()
+		} else {
+			v.fail("properties", "items")
+			if v.done {
+				return
+			}
+		}
+		if x7, ok := o1["payment"]; ok {
+			v.pushKey("payment")
+			if o8, ok := x7.(map[string]interface{}); !ok {
+				v.fail("properties", "payment", "discriminator")
+				if v.done {
+					return
+				}
+			} else if t9, ok := o8["method"]; !ok {
+				v.fail("properties", "payment", "discriminator")
+				if v.done {
+					return
+				}
+			} else if s10, ok := t9.(string); !ok {
+				v.pushKey("method")
+				v.fail("properties", "payment", "discriminator")
+				if v.done {
+					return
+				}
+				v.pop()
+			} else {
+				switch s10 {
+				case "card":
+					if o11, ok := x7.(map[string]interface{}); !ok {
+						v.fail("properties", "payment", "mapping", "card", "properties")
+						if v.done {
+							return
+						}
+					} else {
+						if x12, ok := o11["expMonth"]; ok {
+							v.pushKey("expMonth")
+							if n13, ok := x12.(float64); !ok || n13 != math.Trunc(n13) || n13 < 0 || n13 > 255 {
+								v.fail("properties", "payment", "mapping", "card", "properties", "expMonth", "type")
+								if v.done {
+									return
+								}
+							}
+							v.pop()
+						} else {
+							v.fail("properties", "payment", "mapping", "card", "properties", "expMonth")
+							if v.done {
+								return
+							}
+						}
+						if x14, ok := o11["last4"]; ok {
+							v.pushKey("last4")
+							if _, ok := x14.(string); !ok {
+								v.fail("properties", "payment", "mapping", "card", "properties", "last4", "type")
+								if v.done {
+									return
+								}
+							}
+							v.pop()
+						} else {
+							v.fail("properties", "payment", "mapping", "card", "properties", "last4")
+							if v.done {
+								return
+							}
+						}
+						for k15 := range o11 {
+							switch k15 {
+							case "expMonth", "last4", "method":
+							default:
+								v.pushKey(k15)
+								v.fail("properties", "payment", "mapping", "card")
+								if v.done {
+									return
+								}
+								v.pop()
+							}
+						}
+					}
+				case "invoice":
+					if o16, ok := x7.(map[string]interface{}); !ok {
+						v.fail("properties", "payment", "mapping", "invoice", "properties")
+						if v.done {
+							return
+						}
+					} else {
+						if x17, ok := o16["dueDays"]; ok {
+							v.pushKey("dueDays")
+							if n18, ok := x17.(float64); !ok || n18 != math.Trunc(n18) || n18 < -128 || n18 > 127 {
+								v.fail("properties", "payment", "mapping", "invoice", "properties", "dueDays", "type")
+								if v.done {
+									return
+								}
+							}
+							v.pop()
+						} else {
+							v.fail("properties", "payment", "mapping", "invoice", "properties", "dueDays")
+							if v.done {
+								return
+							}
+						}
+					}
+				default:
+					v.pushKey("method")
+					v.fail("properties", "payment", "mapping")
+					if v.done {
+						return
+					}
+					v.pop()
+				}
+			}
+			v.pop()
+		} else {
+			v.fail("properties", "payment")
+			if v.done {
+				return
+			}
+		}
+		if x19, ok := o1["placedAt"]; ok {
+			v.pushKey("placedAt")
+			if s20, ok := x19.(string); !ok {
+				v.fail("properties", "placedAt", "type")
+				if v.done {
+					return
+				}
+			} else if _, err := time.Parse(time.RFC3339, s20); err != nil {
+				v.fail("properties", "placedAt", "type")
+				if v.done {
+					return
+				}
+			}
+			v.pop()
+		} else {
+			v.fail("properties", "placedAt")
+			if v.done {
+				return
+			}
+		}
+		if x21, ok := o1["discount"]; ok {
+			v.pushKey("discount")
+			if x21 != nil {
+				v.definitionMoney(x21)
+				if v.done {
+					return
+				}
+			}
+			v.pop()
+		}
+		if x23, ok := o1["gift"]; ok {
+			v.pushKey("gift")
+			if _, ok := x23.(bool); !ok {
+				v.fail("optionalProperties", "gift", "type")
+				if v.done {
+					return
+				}
+			}
+			v.pop()
+		}
+		if x24, ok := o1["tags"]; ok {
+			v.pushKey("tags")
+			if o25, ok := x24.(map[string]interface{}); !ok {
+				v.fail("optionalProperties", "tags", "values")
+				if v.done {
+					return
+				}
+			} else {
+				for k26, x27 := range o25 {
+					v.pushKey(k26)
+					if _, ok := x27.(bool); !ok {
+						v.fail("optionalProperties", "tags", "values", "type")
+						if v.done {
+							return
+						}
+					}
+					v.pop()
+				}
+			}
+			v.pop()
+		}
+		if x28, ok := o1["weight"]; ok {
+			v.pushKey("weight")
+			if _, ok := x28.(float64); !ok {
+				v.fail("optionalProperties", "weight", "type")
+				if v.done {
+					return
+				}
+			}
+			v.pop()
+		}
+		for k29 := range o1 {
+			switch k29 {
+			case "id", "items", "payment", "placedAt", "discount", "extra", "gift", "tags", "weight":
+			default:
+				v.pushKey(k29)
+				v.fail()
+				if v.done {
+					return
+				}
+				v.pop()
+			}
+		}
+	}
+}
+
+// definitionItem validates instance against "item" definition.
+func (v *validatorOrder) definitionItem(instance interface{}) {
+	if !v.enter() {
+		return
+	}
+
+	if o1, ok := instance.(map[string]interface{}); !ok {
+		v.fail("definitions", "item", "properties")
+		if v.done {
+			return
+		}
+	} else {
+		if x2, ok := o1["price"]; ok {
+			v.pushKey("price")
+			v.definitionMoney(x2)
+			if v.done {
+				return
+			}
+			v.pop()
+		} else {
+			v.fail("definitions", "item", "properties", "price")
+			if v.done {
+				return
+			}
+		}
+		if x3, ok := o1["quantity"]; ok {
+			v.pushKey("quantity")
+			if n4, ok := x3.(float64); !ok || n4 != math.Trunc(n4) || n4 < 0 || n4 > 65535 {
+				v.fail("definitions", "item", "properties", "quantity", "type")
+				if v.done {
+					return
+				}
+			}
+			v.pop()
+		} else {
+			v.fail("definitions", "item", "properties", "quantity")
+			if v.done {
+				return
+			}
+		}
+		if x5, ok := o1["sku"]; ok {
+			v.pushKey("sku")
+			if _, ok := x5.(string); !ok {
+				v.fail("definitions", "item", "properties", "sku", "type")
+				if v.done {
+					return
+				}
+			}
+			v.pop()
+		} else {
+			v.fail("definitions", "item", "properties", "sku")
+			if v.done {
+				return
+			}
+		}
+		if x6, ok := o1["bundle"]; ok {
+			v.pushKey("bundle")
+			if a7, ok := x6.([]interface{}); !ok {
+				v.fail("definitions", "item", "optionalProperties", "bundle", "elements")
+				if v.done {
+					return
+				}
+			} else {
+				for i8, x9 := range a7 {
+					v.pushIndex(i8)
+					v.definitionItem(x9)
+					if v.done {
+						return
+					}
+					v.pop()
+				}
+			}
+			v.pop()
+		}
+		if x10, ok := o1["notes"]; ok {
+			v.pushKey("notes")
+			if x10 != nil {
+				if _, ok := x10.(string); !ok {
+					v.fail("definitions", "item", "optionalProperties", "notes", "type")
+					if v.done {
+						return
+					}
+				}
+			}
+			v.pop()
+		}
+		for k11 := range o1 {
+			switch k11 {
+			case "price", "quantity", "sku", "bundle", "notes":
+			default:
+				v.pushKey(k11)
+				v.fail("definitions", "item")
+				if v.done {
+					return
+				}
+				v.pop()
+			}
+		}
+	}
+
+	v.depth--
+}
+
+// definitionMoney validates instance against "money" definition.
+func (v *validatorOrder) definitionMoney(instance interface{}) {
+	if !v.enter() {
+		return
+	}
+
+	if o1, ok := instance.(map[string]interface{}); !ok {
+		v.fail("definitions", "money", "properties")
+		if v.done {
+			return
+		}
+	} else {
+		if x2, ok := o1["amount"]; ok {
+			v.pushKey("amount")
+			if n3, ok := x2.(float64); !ok || n3 != math.Trunc(n3) || n3 < -2147483648 || n3 > 2147483647 {
+				v.fail("definitions", "money", "properties", "amount", "type")
+				if v.done {
+					return
+				}
+			}
+			v.pop()
+		} else {
+			v.fail("definitions", "money", "properties", "amount")
+			if v.done {
+				return
+			}
+		}
+		if x4, ok := o1["currency"]; ok {
+			v.pushKey("currency")
+			if s5, ok := x4.(string); !ok {
+				v.fail("definitions", "money", "properties", "currency", "enum")
+				if v.done {
+					return
+				}
+			} else {
+				switch s5 {
+				case "EUR", "JPY", "USD":
+				default:
+					v.fail("definitions", "money", "properties", "currency", "enum")
+					if v.done {
+						return
+					}
+				}
+			}
+			v.pop()
+		} else {
+			v.fail("definitions", "money", "properties", "currency")
+			if v.done {
+				return
+			}
+		}
+		for k6 := range o1 {
+			switch k6 {
+			case "amount", "currency":
+			default:
+				v.pushKey(k6)
+				v.fail("definitions", "money")
+				if v.done {
+					return
+				}
+				v.pop()
+			}
+		}
+	}
+
+	v.depth--
+}
@@ -0,0 +1,5 @@
+// Package validatortest holds a validator generated by "jtd validator", to test
+// that it builds and agrees with jtd.Validate.
+package validatortest
+
+//go:generate go run github.com/jsontypedef/json-typedef-go/cmd/jtd validator -o order_validator.go order.jtd.json
@@ -0,0 +1,116 @@
+package validatortest_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/gogen/internal/validatortest"
+	"github.com/stretchr/testify/assert"
+)
+
+var instances = []string{
+	`null`,
+	`[]`,
+	`{}`,
+	`{"id": "a", "placedAt": "2020-01-01T00:00:00Z", "items": [], "payment": {"method": "card", "last4": "1234", "expMonth": 12}}`,
+	`{"id": 1, "placedAt": "yesterday", "items": {}, "payment": {"method": "cash"}, "foo": true}`,
+	`{"id": "a", "placedAt": "2020-01-01T00:00:00Z", "payment": {"method": 1}, "items": [
+		{"sku": "x", "quantity": 1.5, "price": {"amount": 3000000000, "currency": "GBP"}, "notes": null},
+		{"sku": "y", "quantity": 65536, "price": {"amount": -1, "currency": "EUR", "extra": 1}, "bundle": [
+			{"sku": "z", "quantity": 0, "price": null, "notes": 1}
+		]}
+	]}`,
+	`{"id": "a", "placedAt": "2020-01-01T00:00:00+01:00", "items": [], "payment": {"method": "invoice", "dueDays": -129, "po": "123"},
+		"discount": null, "tags": {"a": true, "b": "false"}, "gift": "yes", "weight": "heavy", "extra": [1, 2]}`,
+	`{"id": "a", "placedAt": "2020-01-01T00:00:00Z", "items": [], "payment": {"method": "card", "expMonth": 256, "cvc": "123"},
+		"discount": {"amount": 5, "currency": "USD"}}`,
+}
+
+func sortErrors(errs []jtd.ValidateError) {
+	sort.Slice(errs, func(i, j int) bool {
+		a := strings.Join(errs[i].SchemaPath, "/") + ":" + strings.Join(errs[i].InstancePath, "/")
+		b := strings.Join(errs[j].SchemaPath, "/") + ":" + strings.Join(errs[j].InstancePath, "/")
+		return a < b
+	})
+}
+
+func TestValidateOrder(t *testing.T) {
+	data, err := ioutil.ReadFile("order.jtd.json")
+	assert.NoError(t, err)
+
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal(data, &schema))
+
+	for _, s := range instances {
+		var instance interface{}
+		assert.NoError(t, json.Unmarshal([]byte(s), &instance))
+
+		expected, err := jtd.Validate(schema, instance)
+		assert.NoError(t, err)
+
+		actual, err := validatortest.ValidateOrder(instance)
+		assert.NoError(t, err)
+
+		sortErrors(expected)
+		sortErrors(actual)
+		assert.Equal(t, expected, actual, s)
+
+		actual, err = validatortest.ValidateOrder(instance, jtd.WithMaxErrors(1))
+		assert.NoError(t, err)
+		assert.Equal(t, len(expected) > 0, len(actual) == 1, s)
+	}
+}
+
+func TestValidateOrderMaxDepth(t *testing.T) {
+	instance := map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{
+			"bundle": []interface{}{map[string]interface{}{}},
+		}},
+	}
+
+	_, err := validatortest.ValidateOrder(instance, jtd.WithMaxDepth(2))
+	assert.Equal(t, jtd.ErrMaxDepthExceeded, err)
+
+	_, err = validatortest.ValidateOrder(instance, jtd.WithMaxDepth(3))
+	assert.NoError(t, err)
+}
+
+func BenchmarkValidateOrder(b *testing.B) {
+	var instance interface{}
+	if err := json.Unmarshal([]byte(instances[3]), &instance); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		validatortest.ValidateOrder(instance)
+	}
+}
+
+func BenchmarkCompiledSchemaValidate(b *testing.B) {
+	data, err := ioutil.ReadFile("order.jtd.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var schema jtd.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		b.Fatal(err)
+	}
+
+	compiled := jtd.MustCompile(schema)
+
+	var instance interface{}
+	if err := json.Unmarshal([]byte(instances[3]), &instance); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		compiled.Validate(instance)
+	}
+}
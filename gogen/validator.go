@@ -0,0 +1,395 @@
+package gogen
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// GenerateValidator writes a Go file of package pkg to w with a function that
+// validates instances against schema, without interpreting schema at runtime:
+//
+//	func ValidateName(instance interface{}, opts ...jtd.ValidateOption) ([]jtd.ValidateError, error)
+//
+// The function returns the same errors as jtd.Validate, though not necessarily
+// in the same order, and so when MaxErrors is set, not necessarily the same
+// subset of them. MaxDepth is supported as well.
+//
+// Each definition of schema becomes a method of its own, and everything else
+// is turned into straight-line code: discriminators switch on their tag,
+// integer types are range checks, and so on.
+//
+// GenerateValidator returns an error if schema is not valid according to
+// Schema.Validate, or wraps jtd.ErrUnproductiveRefCycle if schema has ref
+// cycles that generated code could follow forever.
+func GenerateValidator(w io.Writer, pkg, name string, schema jtd.Schema) error {
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	if err := schema.Validate(); err != nil {
+		return err
+	}
+
+	if cycles := schema.RefCycles(); cycles != nil {
+		return fmt.Errorf("%w: %s", jtd.ErrUnproductiveRefCycle, strings.Join(cycles[0], ", "))
+	}
+
+	g := validatorGenerator{
+		validator: "validator" + name,
+		token:     "pathToken" + name,
+		methods:   map[string]string{},
+		used:      map[string]bool{"root": true},
+	}
+
+	for _, def := range schemautil.SortedKeys(schema.Definitions) {
+		method := "definition" + ExportedName(def)
+		for i := 2; g.used[method]; i++ {
+			method = fmt.Sprintf("definition%s%d", ExportedName(def), i)
+		}
+
+		g.methods[def] = method
+		g.used[method] = true
+	}
+
+	g.method("root", "the root schema", []string{}, schema, false)
+	for _, def := range schemautil.SortedKeys(schema.Definitions) {
+		g.method(g.methods[def], strconv.Quote(def)+" definition", []string{"definitions", def}, schema.Definitions[def], true)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by jtd validator. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n")
+	if g.usesMath {
+		b.WriteString("\"math\"\n")
+	}
+
+	b.WriteString("\"strconv\"\n")
+	if g.usesTime {
+		b.WriteString("\"time\"\n")
+	}
+
+	fmt.Fprintf(&b, "\njtd %q\n)\n", "github.com/jsontypedef/json-typedef-go")
+	fmt.Fprintf(&b, validatorPrelude, name, g.validator, g.token)
+	b.WriteString(g.b.String())
+
+	out, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// validatorPrelude is the part of generated validators that doesn't depend on
+// the schema. Its verbs are the name of the schema, and the names of the
+// validator and path token types.
+const validatorPrelude = `
+// Validate%[1]s validates instance against the %[1]s schema. It returns the
+// same errors as jtd.Validate, though not necessarily in the same order.
+func Validate%[1]s(instance interface{}, opts ...jtd.ValidateOption) ([]jtd.ValidateError, error) {
+	v := %[2]s{}
+	for _, opt := range opts {
+		opt(&v.settings)
+	}
+
+	v.root(instance)
+	if v.err != nil {
+		return nil, v.err
+	}
+
+	if v.errors == nil {
+		return []jtd.ValidateError{}, nil
+	}
+
+	return v.errors, nil
+}
+
+// %[3]s is a token of an instance path. Index is -1 for object keys.
+type %[3]s struct {
+	key   string
+	index int
+}
+
+// %[2]s is the state of an ongoing validation.
+type %[2]s struct {
+	settings     jtd.ValidateSettings
+	errors       []jtd.ValidateError
+	instancePath []%[3]s
+
+	// depth is the number of refs being followed.
+	depth int
+
+	// done is set when validation must stop, because MaxErrors was reached or
+	// because of err.
+	done bool
+	err  error
+}
+
+func (v *%[2]s) pushKey(key string) {
+	v.instancePath = append(v.instancePath, %[3]s{key: key, index: -1})
+}
+
+func (v *%[2]s) pushIndex(index int) {
+	v.instancePath = append(v.instancePath, %[3]s{index: index})
+}
+
+func (v *%[2]s) pop() {
+	v.instancePath = v.instancePath[:len(v.instancePath)-1]
+}
+
+func (v *%[2]s) fail(schemaPath ...string) {
+	instancePath := make([]string, len(v.instancePath))
+	for i, t := range v.instancePath {
+		if t.index == -1 {
+			instancePath[i] = t.key
+		} else {
+			instancePath[i] = strconv.Itoa(t.index)
+		}
+	}
+
+	if schemaPath == nil {
+		schemaPath = []string{}
+	}
+
+	v.errors = append(v.errors, jtd.ValidateError{InstancePath: instancePath, SchemaPath: schemaPath})
+	if len(v.errors) == v.settings.MaxErrors {
+		v.done = true
+	}
+}
+
+// enter is called before following a ref. It returns false if MaxDepth was
+// reached.
+func (v *%[2]s) enter() bool {
+	if v.depth+1 == v.settings.MaxDepth {
+		v.err = jtd.ErrMaxDepthExceeded
+		v.done = true
+		return false
+	}
+
+	v.depth++
+	return true
+}
+`
+
+type validatorGenerator struct {
+	b strings.Builder
+
+	// validator and token are the names of the generated types.
+	validator string
+	token     string
+
+	// methods maps definition names to the names of their methods. used is the
+	// set of method names already taken.
+	methods map[string]string
+	used    map[string]bool
+
+	// vars is used to name the variables of the method being generated.
+	vars int
+
+	usesMath bool
+	usesTime bool
+}
+
+// method writes a method called name that validates its argument against s.
+// schemaPath is the schema path of s, and isDefinition is whether s is a
+// definition, which must be entered as a ref is followed.
+func (g *validatorGenerator) method(name, description string, schemaPath []string, s jtd.Schema, isDefinition bool) {
+	g.vars = 0
+
+	fmt.Fprintf(&g.b, "\n// %s validates instance against %s.\n", name, description)
+	fmt.Fprintf(&g.b, "func (v *%s) %s(instance interface{}) {\n", g.validator, name)
+
+	if isDefinition {
+		g.b.WriteString("if !v.enter() {\nreturn\n}\n\n")
+	}
+
+	g.b.WriteString(g.node(schemaPath, s, "instance", ""))
+
+	if isDefinition {
+		g.b.WriteString("\nv.depth--\n")
+	}
+
+	g.b.WriteString("}\n")
+}
+
+// newVar returns a new variable name starting with prefix.
+func (g *validatorGenerator) newVar(prefix string) string {
+	g.vars++
+	return fmt.Sprintf("%s%d", prefix, g.vars)
+}
+
+// fail returns the statements that record an error at schemaPath.
+func (g *validatorGenerator) fail(schemaPath ...string) string {
+	args := make([]string, len(schemaPath))
+	for i, token := range schemaPath {
+		args[i] = strconv.Quote(token)
+	}
+
+	return fmt.Sprintf("v.fail(%s)\nif v.done {\nreturn\n}\n", strings.Join(args, ", "))
+}
+
+// node returns the statements that validate the variable x against s, or the
+// empty string if any value of x is valid. schemaPath is the schema path of s,
+// and tag is the discriminator tag that s is a mapping of, if any.
+func (g *validatorGenerator) node(schemaPath []string, s jtd.Schema, x, tag string) string {
+	var b strings.Builder
+	path := func(tokens ...string) []string {
+		return append(append([]string{}, schemaPath...), tokens...)
+	}
+
+	switch s.Form() {
+	case jtd.FormEmpty:
+		return ""
+	case jtd.FormRef:
+		fmt.Fprintf(&b, "v.%s(%s)\nif v.done {\nreturn\n}\n", g.methods[*s.Ref], x)
+	case jtd.FormType:
+		fail := g.fail(path("type")...)
+
+		switch s.Type {
+		case jtd.TypeBoolean:
+			fmt.Fprintf(&b, "if _, ok := %s.(bool); !ok {\n%s}\n", x, fail)
+		case jtd.TypeFloat32, jtd.TypeFloat64:
+			fmt.Fprintf(&b, "if _, ok := %s.(float64); !ok {\n%s}\n", x, fail)
+		case jtd.TypeString:
+			fmt.Fprintf(&b, "if _, ok := %s.(string); !ok {\n%s}\n", x, fail)
+		case jtd.TypeTimestamp:
+			g.usesTime = true
+			str := g.newVar("s")
+			fmt.Fprintf(&b, "if %s, ok := %s.(string); !ok {\n%s} else if _, err := time.Parse(time.RFC3339, %s); err != nil {\n%s}\n", str, x, fail, str, fail)
+		default:
+			g.usesMath = true
+			n := g.newVar("n")
+			r := numberRanges[s.Type]
+			fmt.Fprintf(&b, "if %s, ok := %s.(float64); !ok || %s != math.Trunc(%s) || %s < %s || %s > %s {\n%s}\n",
+				n, x, n, n, n, strconv.FormatFloat(r[0], 'f', -1, 64), n, strconv.FormatFloat(r[1], 'f', -1, 64), fail)
+		}
+	case jtd.FormEnum:
+		str := g.newVar("s")
+		values := make([]string, len(s.Enum))
+		for i, value := range s.Enum {
+			values[i] = strconv.Quote(value)
+		}
+
+		fail := g.fail(path("enum")...)
+		fmt.Fprintf(&b, "if %s, ok := %s.(string); !ok {\n%s} else {\nswitch %s {\ncase %s:\ndefault:\n%s}\n}\n",
+			str, x, fail, str, strings.Join(values, ", "), fail)
+	case jtd.FormElements:
+		arr := g.newVar("a")
+		i := g.newVar("i")
+		elem := g.newVar("x")
+		body := g.node(path("elements"), *s.Elements, elem, "")
+
+		if body == "" {
+			fmt.Fprintf(&b, "if _, ok := %s.([]interface{}); !ok {\n%s}\n", x, g.fail(path("elements")...))
+		} else {
+			fmt.Fprintf(&b, "if %s, ok := %s.([]interface{}); !ok {\n%s} else {\n", arr, x, g.fail(path("elements")...))
+			fmt.Fprintf(&b, "for %s, %s := range %s {\nv.pushIndex(%s)\n%sv.pop()\n}\n}\n", i, elem, arr, i, body)
+		}
+	case jtd.FormProperties:
+		keyword := "properties"
+		if s.Properties == nil {
+			keyword = "optionalProperties"
+		}
+
+		obj := g.newVar("o")
+		var checks strings.Builder
+		var known []string
+
+		for _, name := range schemautil.SortedKeys(s.Properties) {
+			known = append(known, strconv.Quote(name))
+			prop := g.newVar("x")
+			fail := g.fail(path("properties", name)...)
+			if body := g.node(path("properties", name), s.Properties[name], prop, ""); body == "" {
+				fmt.Fprintf(&checks, "if _, ok := %s[%q]; !ok {\n%s}\n", obj, name, fail)
+			} else {
+				fmt.Fprintf(&checks, "if %s, ok := %s[%q]; ok {\nv.pushKey(%q)\n%sv.pop()\n} else {\n%s}\n", prop, obj, name, name, body, fail)
+			}
+		}
+
+		for _, name := range schemautil.SortedKeys(s.OptionalProperties) {
+			known = append(known, strconv.Quote(name))
+			prop := g.newVar("x")
+			body := g.node(path("optionalProperties", name), s.OptionalProperties[name], prop, "")
+			if body != "" {
+				fmt.Fprintf(&checks, "if %s, ok := %s[%q]; ok {\nv.pushKey(%q)\n%sv.pop()\n}\n", prop, obj, name, name, body)
+			}
+		}
+
+		if !s.AdditionalProperties {
+			if tag != "" {
+				known = append(known, strconv.Quote(tag))
+			}
+
+			key := g.newVar("k")
+			fail := fmt.Sprintf("v.pushKey(%s)\n%sv.pop()\n", key, g.fail(schemaPath...))
+			if len(known) == 0 {
+				fmt.Fprintf(&checks, "for %s := range %s {\n%s}\n", key, obj, fail)
+			} else {
+				fmt.Fprintf(&checks, "for %s := range %s {\nswitch %s {\ncase %s:\ndefault:\n%s}\n}\n", key, obj, key, strings.Join(known, ", "), fail)
+			}
+		}
+
+		if checks.Len() == 0 {
+			obj = "_"
+		}
+
+		fmt.Fprintf(&b, "if %s, ok := %s.(map[string]interface{}); !ok {\n%s}", obj, x, g.fail(path(keyword)...))
+		if checks.Len() == 0 {
+			b.WriteString("\n")
+		} else {
+			fmt.Fprintf(&b, " else {\n%s}\n", checks.String())
+		}
+	case jtd.FormValues:
+		obj := g.newVar("o")
+		key := g.newVar("k")
+		elem := g.newVar("x")
+		body := g.node(path("values"), *s.Values, elem, "")
+
+		if body == "" {
+			fmt.Fprintf(&b, "if _, ok := %s.(map[string]interface{}); !ok {\n%s}\n", x, g.fail(path("values")...))
+		} else {
+			fmt.Fprintf(&b, "if %s, ok := %s.(map[string]interface{}); !ok {\n%s} else {\n", obj, x, g.fail(path("values")...))
+			fmt.Fprintf(&b, "for %s, %s := range %s {\nv.pushKey(%s)\n%sv.pop()\n}\n}\n", key, elem, obj, key, body)
+		}
+	case jtd.FormDiscriminator:
+		obj := g.newVar("o")
+		tagValue := g.newVar("t")
+		str := g.newVar("s")
+		fail := g.fail(path("discriminator")...)
+
+		fmt.Fprintf(&b, "if %s, ok := %s.(map[string]interface{}); !ok {\n%s}", obj, x, fail)
+		fmt.Fprintf(&b, " else if %s, ok := %s[%q]; !ok {\n%s}", tagValue, obj, s.Discriminator, fail)
+		fmt.Fprintf(&b, " else if %s, ok := %s.(string); !ok {\nv.pushKey(%q)\n%sv.pop()\n}", str, tagValue, s.Discriminator, fail)
+		fmt.Fprintf(&b, " else {\nswitch %s {\n", str)
+		for _, name := range schemautil.SortedKeys(s.Mapping) {
+			fmt.Fprintf(&b, "case %q:\n%s", name, g.node(path("mapping", name), s.Mapping[name], x, s.Discriminator))
+		}
+
+		fmt.Fprintf(&b, "default:\nv.pushKey(%q)\n%sv.pop()\n}\n}\n", s.Discriminator, g.fail(path("mapping")...))
+	}
+
+	if s.Nullable {
+		return fmt.Sprintf("if %s != nil {\n%s}\n", x, b.String())
+	}
+
+	return b.String()
+}
+
+// numberRanges are the ranges of numbers that the integer types accept.
+var numberRanges = map[jtd.Type][2]float64{
+	jtd.TypeInt8:   {-128, 127},
+	jtd.TypeUint8:  {0, 255},
+	jtd.TypeInt16:  {-32768, 32767},
+	jtd.TypeUint16: {0, 65535},
+	jtd.TypeInt32:  {-2147483648, 2147483647},
+	jtd.TypeUint32: {0, 4294967295},
+}
@@ -0,0 +1,235 @@
+package gogen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/gogen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateValidator(t *testing.T) {
+	// The validatortest package holds the output of "jtd validator" for its
+	// schema, and tests it against jtd.Validate. Make sure that it's up to date.
+	dir := filepath.Join("internal", "validatortest")
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "order.jtd.json"))
+	assert.NoError(t, err)
+
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal(data, &schema))
+
+	var b bytes.Buffer
+	assert.NoError(t, gogen.GenerateValidator(&b, "validatortest", "Order", schema))
+
+	expected, err := ioutil.ReadFile(filepath.Join(dir, "order_validator.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), b.String(), "run go generate ./gogen/...")
+}
+
+func TestGenerateValidatorErrors(t *testing.T) {
+	a := "a"
+	var b bytes.Buffer
+
+	err := gogen.GenerateValidator(&b, "foo", "order", jtd.Schema{})
+	assert.True(t, errors.Is(err, gogen.ErrInvalidName))
+
+	err = gogen.GenerateValidator(&b, "foo", "Order", jtd.Schema{Ref: &a})
+	assert.True(t, errors.Is(err, jtd.ErrNoSuchDefinition))
+
+	err = gogen.GenerateValidator(&b, "foo", "Order", jtd.Schema{
+		Definitions: map[string]jtd.Schema{"a": {Ref: &a}},
+	})
+	assert.True(t, errors.Is(err, jtd.ErrUnproductiveRefCycle))
+}
+
+// validationCase is a test case in the format of the spec's validation test
+// suite.
+type validationCase struct {
+	Schema   jtd.Schema  `json:"schema"`
+	Instance interface{} `json:"instance"`
+	Errors   []struct {
+		InstancePath []string `json:"instancePath"`
+		SchemaPath   []string `json:"schemaPath"`
+	} `json:"errors"`
+}
+
+// skippedSpecCases are the cases of the spec's test suite that jtd.Validate
+// doesn't pass either, because the time package doesn't support leap seconds.
+var skippedSpecCases = map[string]bool{
+	"timestamp type schema - 1990-12-31T23:59:60Z":      true,
+	"timestamp type schema - 1990-12-31T15:59:60-08:00": true,
+}
+
+func TestGenerateValidatorConformance(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping building generated code in short mode")
+	}
+
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available:", err)
+	}
+
+	// The spec's test suite is a git submodule, which CI always checks out.
+	path := "../json-typedef-spec/tests/validation.json"
+	if _, err := os.Stat(path); err != nil {
+		if os.Getenv("CI") != "" {
+			t.Fatal("spec test suite not available:", err)
+		}
+
+		t.Skip("spec test suite not available:", err)
+	}
+
+	runConformance(t, goTool, path)
+}
+
+// conformanceMain is the program that runs generated validators against their
+// test cases, which are in cases.json.
+const conformanceMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+)
+
+type testCase struct {
+	Name     string
+	Instance interface{}
+	Errors   []jtd.ValidateError
+}
+
+func sortErrors(errs []jtd.ValidateError) {
+	sort.Slice(errs, func(i, j int) bool {
+		a := strings.Join(errs[i].SchemaPath, "/") + ":" + strings.Join(errs[i].InstancePath, "/")
+		b := strings.Join(errs[j].SchemaPath, "/") + ":" + strings.Join(errs[j].InstancePath, "/")
+		return a < b
+	})
+}
+
+func main() {
+	data, err := ioutil.ReadFile("cases.json")
+	if err != nil {
+		panic(err)
+	}
+
+	var testCases []testCase
+	if err := json.Unmarshal(data, &testCases); err != nil {
+		panic(err)
+	}
+
+	failed := false
+	for i, tt := range testCases {
+		errs, err := validators[i](tt.Instance)
+		sortErrors(errs)
+		sortErrors(tt.Errors)
+
+		if err != nil || !reflect.DeepEqual(errs, tt.Errors) {
+			fmt.Printf("%s: got %v, %v, want %v\n", tt.Name, errs, err, tt.Errors)
+			failed = true
+		}
+
+		errs, err = validators[i](tt.Instance, jtd.WithMaxErrors(1))
+		if err != nil || (len(tt.Errors) > 0) != (len(errs) == 1) {
+			fmt.Printf("%s: with MaxErrors(1), got %v, %v\n", tt.Name, errs, err)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+`
+
+// runConformance generates a validator for each test case in path, and runs
+// them in a program of their own.
+func runConformance(t *testing.T, goTool, path string) {
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+
+	var testCases map[string]validationCase
+	assert.NoError(t, json.Unmarshal(data, &testCases))
+
+	root, err := filepath.Abs("..")
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	goMod := fmt.Sprintf("module conformance\n\ngo 1.16\n\nrequire github.com/jsontypedef/json-typedef-go v0.0.0\n\nreplace github.com/jsontypedef/json-typedef-go => %q\n", root)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644))
+
+	goSum, err := ioutil.ReadFile(filepath.Join(root, "go.sum"))
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0o644))
+
+	names := make([]string, 0, len(testCases))
+	for name := range testCases {
+		if !skippedSpecCases[name] {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	type mainCase struct {
+		Name     string
+		Instance interface{}
+		Errors   []jtd.ValidateError
+	}
+
+	var cases []mainCase
+	var validators strings.Builder
+	for _, name := range names {
+		tt := testCases[name]
+		id := fmt.Sprintf("Case%d", len(cases))
+
+		var b bytes.Buffer
+		if err := gogen.GenerateValidator(&b, "main", id, tt.Schema); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, strings.ToLower(id)+".go"), b.Bytes(), 0o644))
+		fmt.Fprintf(&validators, "Validate%s,\n", id)
+
+		errs := []jtd.ValidateError{}
+		for _, e := range tt.Errors {
+			errs = append(errs, jtd.ValidateError{InstancePath: e.InstancePath, SchemaPath: e.SchemaPath})
+		}
+
+		cases = append(cases, mainCase{Name: name, Instance: tt.Instance, Errors: errs})
+	}
+
+	casesJSON, err := json.Marshal(cases)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cases.json"), casesJSON, 0o644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(conformanceMain), 0o644))
+
+	validatorsGo := fmt.Sprintf("package main\n\nimport jtd %q\n\nvar validators = []func(interface{}, ...jtd.ValidateOption) ([]jtd.ValidateError, error){\n%s}\n",
+		"github.com/jsontypedef/json-typedef-go", validators.String())
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "validators.go"), []byte(validatorsGo), 0o644))
+
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, "%s", out)
+}
@@ -0,0 +1,50 @@
+// Package schemautil has the helpers that the packages of this module share for
+// walking schemas in a stable order and reporting where things are in them.
+//
+// The jtd package can't import schemautil, since schemautil depends on it, so
+// it has its own copies of these helpers.
+package schemautil
+
+import (
+	"sort"
+	"strings"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+)
+
+// AppendPath returns a new path consisting of path followed by tokens. The
+// returned slice never shares memory with path.
+func AppendPath(path []string, tokens ...string) []string {
+	out := make([]string, 0, len(path)+len(tokens))
+	out = append(out, path...)
+	return append(out, tokens...)
+}
+
+// SortedKeys returns the keys of schemas in sorted order.
+func SortedKeys(schemas map[string]jtd.Schema) []string {
+	keys := make([]string, 0, len(schemas))
+	for key := range schemas {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+var tokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// EscapeToken escapes token for use as a reference token of a JSON Pointer.
+func EscapeToken(token string) string {
+	return tokenEscaper.Replace(token)
+}
+
+// Pointer returns the JSON Pointer made up of the given tokens.
+func Pointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(EscapeToken(token))
+	}
+
+	return b.String()
+}
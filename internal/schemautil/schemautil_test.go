@@ -0,0 +1,35 @@
+package schemautil_test
+
+import (
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendPath(t *testing.T) {
+	path := make([]string, 1, 10)
+	path[0] = "properties"
+
+	a := schemautil.AppendPath(path, "a")
+	b := schemautil.AppendPath(path, "b")
+	assert.Equal(t, []string{"properties", "a"}, a)
+	assert.Equal(t, []string{"properties", "b"}, b)
+}
+
+func TestSortedKeys(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, schemautil.SortedKeys(map[string]jtd.Schema{
+		"c": jtd.Schema{},
+		"a": jtd.Schema{},
+		"b": jtd.Schema{},
+	}))
+
+	assert.Empty(t, schemautil.SortedKeys(nil))
+}
+
+func TestPointer(t *testing.T) {
+	assert.Equal(t, "", schemautil.Pointer(nil))
+	assert.Equal(t, "/properties/a~1b~0c", schemautil.Pointer([]string{"properties", "a/b~c"}))
+	assert.Equal(t, "a~1b~0c", schemautil.EscapeToken("a/b~c"))
+}
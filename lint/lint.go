@@ -0,0 +1,136 @@
+// Package lint checks JSON Typedef schemas against style rules that go beyond
+// what the JSON Typedef specification requires.
+//
+// Rules are plain values, so you can configure the built-in rules (e.g. by
+// changing their Severity) or write your own:
+//
+//	rules := lint.DefaultRules()
+//	rules = append(rules, lint.Rule{
+//		Name:     "no-float32",
+//		Severity: lint.SeverityWarning,
+//		Check: func(path []string, s jtd.Schema) []lint.Finding {
+//			if s.Type == jtd.TypeFloat32 {
+//				return []lint.Finding{{Message: "use float64 instead of float32"}}
+//			}
+//
+//			return nil
+//		},
+//	})
+//
+//	findings := lint.Lint(schema, rules...)
+package lint
+
+import (
+	"fmt"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// Severity indicates how serious a Finding is.
+type Severity int
+
+const (
+	// SeverityInfo is for findings that are merely informative.
+	SeverityInfo Severity = iota
+
+	// SeverityWarning is for findings that should usually be fixed.
+	SeverityWarning
+
+	// SeverityError is for findings that must be fixed.
+	SeverityError
+)
+
+// String returns "info", "warning", or "error".
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// ParseSeverity is the inverse of Severity.String.
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range []Severity{SeverityInfo, SeverityWarning, SeverityError} {
+		if s == severity.String() {
+			return severity, nil
+		}
+	}
+
+	return 0, fmt.Errorf("lint: invalid severity: %q", s)
+}
+
+// Finding is a problem found by a Rule.
+type Finding struct {
+	// The name of the rule that produced the finding.
+	Rule string
+
+	// The severity of the finding.
+	Severity Severity
+
+	// Path to the part of the schema the finding is about, using the same tokens
+	// as the SchemaPath of jtd.ValidateError.
+	SchemaPath []string
+
+	// A human-readable description of the finding.
+	Message string
+}
+
+// Pointer returns SchemaPath as a JSON Pointer.
+func (f Finding) Pointer() string {
+	return schemautil.Pointer(f.SchemaPath)
+}
+
+// String formats f as "severity: pointer: message (rule)".
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Severity, f.Pointer(), f.Message, f.Rule)
+}
+
+// Rule is a check that Lint runs against every schema in a tree.
+type Rule struct {
+	// A short, unique, kebab-case name for the rule.
+	Name string
+
+	// The severity of the findings of the rule.
+	Severity Severity
+
+	// Check returns the findings of the rule for s, which is at path within the
+	// root schema.
+	//
+	// Check only needs to fill in the Message of each finding, and its
+	// SchemaPath if the finding is about a part of s rather than s itself. Lint
+	// fills in everything else.
+	Check func(path []string, s jtd.Schema) []Finding
+}
+
+// Lint runs rules against schema and all of its subschemas, and returns their
+// findings.
+//
+// Findings are ordered by the position of the schema they were found in, as
+// visited by jtd.Walk, and then by the order of rules.
+func Lint(schema jtd.Schema, rules ...Rule) []Finding {
+	var findings []Finding
+	jtd.Walk(schema, func(path []string, s *jtd.Schema) error {
+		for _, rule := range rules {
+			for _, f := range rule.Check(path, *s) {
+				if f.SchemaPath == nil {
+					f.SchemaPath = path
+				}
+
+				f.Rule = rule.Name
+				f.Severity = rule.Severity
+				findings = append(findings, f)
+			}
+		}
+
+		return nil
+	})
+
+	return findings
+}
@@ -0,0 +1,114 @@
+package lint_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/lint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"documented": {
+				"metadata": { "description": "A documented definition." },
+				"enum": ["FOO", "FOO_BAR2"]
+			},
+			"undocumented": {
+				"enum": ["foo", "Bar"]
+			}
+		},
+		"properties": {
+			"camelCase": { "type": "string" },
+			"snake_case": { "type": "string" },
+			"anything": {}
+		},
+		"optionalProperties": {
+			"Kind": {
+				"discriminator": "kind",
+				"mapping": {
+					"a": { "properties": {}, "additionalProperties": true }
+				}
+			}
+		}
+	}`), &schema))
+
+	var actual []string
+	for _, f := range lint.Lint(schema, lint.BuiltinRules()...) {
+		actual = append(actual, f.String())
+	}
+
+	assert.Equal(t, []string{
+		"warning: /properties/snake_case: property name \"snake_case\" is not camelCase (camel-case-properties)",
+		"warning: /optionalProperties/Kind: property name \"Kind\" is not camelCase (camel-case-properties)",
+		"warning: /definitions/undocumented: definition \"undocumented\" has no description (definition-descriptions)",
+		"warning: /definitions/undocumented/enum: enum value \"foo\" is not UPPER_SNAKE case (upper-snake-enums)",
+		"warning: /definitions/undocumented/enum: enum value \"Bar\" is not UPPER_SNAKE case (upper-snake-enums)",
+		"error: /properties/anything: schema accepts any value (no-empty-form)",
+		"warning: /optionalProperties/Kind/discriminator: discriminator is \"kind\", not \"type\" (discriminator-name)",
+		"warning: /optionalProperties/Kind/mapping/a/additionalProperties: schema allows additional properties (no-additional-properties)",
+	}, actual)
+}
+
+func TestDefaultRules(t *testing.T) {
+	for _, rule := range lint.DefaultRules() {
+		assert.NotEqual(t, "no-additional-properties", rule.Name)
+	}
+
+	assert.Len(t, lint.DefaultRules(), len(lint.BuiltinRules())-1)
+}
+
+func TestNoEmptyFormRoot(t *testing.T) {
+	library := jtd.Schema{
+		Definitions: map[string]jtd.Schema{"a": jtd.Schema{Type: jtd.TypeString}},
+	}
+
+	assert.Empty(t, lint.Lint(library, lint.NoEmptyForm()))
+	assert.Len(t, lint.Lint(jtd.Schema{}, lint.NoEmptyForm()), 1)
+}
+
+func TestFindingPointer(t *testing.T) {
+	f := lint.Finding{SchemaPath: []string{"properties", "a/b~c"}}
+	assert.Equal(t, "/properties/a~1b~0c", f.Pointer())
+	assert.Equal(t, "", lint.Finding{}.Pointer())
+}
+
+func TestParseSeverity(t *testing.T) {
+	severity, err := lint.ParseSeverity("warning")
+	assert.NoError(t, err)
+	assert.Equal(t, lint.SeverityWarning, severity)
+
+	_, err = lint.ParseSeverity("fatal")
+	assert.Error(t, err)
+}
+
+func ExampleLint() {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"user_id": jtd.Schema{Type: jtd.TypeFloat32},
+		},
+	}
+
+	noFloat32 := lint.Rule{
+		Name:     "no-float32",
+		Severity: lint.SeverityError,
+		Check: func(path []string, s jtd.Schema) []lint.Finding {
+			if s.Type == jtd.TypeFloat32 {
+				return []lint.Finding{{Message: "use float64 instead of float32"}}
+			}
+
+			return nil
+		},
+	}
+
+	for _, f := range lint.Lint(schema, lint.CamelCaseProperties(), noFloat32) {
+		fmt.Println(f)
+	}
+	// Output:
+	// warning: /properties/user_id: property name "user_id" is not camelCase (camel-case-properties)
+	// error: /properties/user_id: use float64 instead of float32 (no-float32)
+}
@@ -0,0 +1,171 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// BuiltinRules returns all of the built-in rules, with their default
+// configuration.
+func BuiltinRules() []Rule {
+	return []Rule{
+		CamelCaseProperties(),
+		DefinitionDescriptions(),
+		NoEmptyForm(),
+		UpperSnakeEnums(),
+		NoAdditionalProperties(),
+		DiscriminatorName("type"),
+	}
+}
+
+// DefaultRules returns the built-in rules that are enabled by default. That's
+// all of them except NoAdditionalProperties, which only makes sense for some
+// schemas.
+func DefaultRules() []Rule {
+	var rules []Rule
+	for _, rule := range BuiltinRules() {
+		if rule.Name != "no-additional-properties" {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+var camelCase = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+
+// CamelCaseProperties returns a rule that requires property names to be
+// camelCase.
+func CamelCaseProperties() Rule {
+	return Rule{
+		Name:     "camel-case-properties",
+		Severity: SeverityWarning,
+		Check: func(path []string, s jtd.Schema) []Finding {
+			var findings []Finding
+			for _, keyword := range []string{"properties", "optionalProperties"} {
+				properties := s.Properties
+				if keyword == "optionalProperties" {
+					properties = s.OptionalProperties
+				}
+
+				for _, name := range schemautil.SortedKeys(properties) {
+					if !camelCase.MatchString(name) {
+						findings = append(findings, Finding{
+							SchemaPath: schemautil.AppendPath(path, keyword, name),
+							Message:    fmt.Sprintf("property name %q is not camelCase", name),
+						})
+					}
+				}
+			}
+
+			return findings
+		},
+	}
+}
+
+// DefinitionDescriptions returns a rule that requires every definition to have
+// a non-empty string "description" in its metadata.
+func DefinitionDescriptions() Rule {
+	return Rule{
+		Name:     "definition-descriptions",
+		Severity: SeverityWarning,
+		Check: func(path []string, s jtd.Schema) []Finding {
+			if len(path) != 2 || path[0] != "definitions" {
+				return nil
+			}
+
+			if s.Description() != "" {
+				return nil
+			}
+
+			return []Finding{{Message: fmt.Sprintf("definition %q has no description", path[1])}}
+		},
+	}
+}
+
+// NoEmptyForm returns a rule that forbids schemas of the empty form, which
+// accept any value at all.
+//
+// A root schema of the empty form that has definitions is allowed, because such
+// a schema is usually a library of definitions rather than a contract itself.
+func NoEmptyForm() Rule {
+	return Rule{
+		Name:     "no-empty-form",
+		Severity: SeverityError,
+		Check: func(path []string, s jtd.Schema) []Finding {
+			if s.Form() != jtd.FormEmpty || (len(path) == 0 && len(s.Definitions) > 0) {
+				return nil
+			}
+
+			return []Finding{{Message: "schema accepts any value"}}
+		},
+	}
+}
+
+var upperSnakeCase = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+
+// UpperSnakeEnums returns a rule that requires enum values to be UPPER_SNAKE
+// case.
+func UpperSnakeEnums() Rule {
+	return Rule{
+		Name:     "upper-snake-enums",
+		Severity: SeverityWarning,
+		Check: func(path []string, s jtd.Schema) []Finding {
+			var findings []Finding
+			for _, value := range s.Enum {
+				if !upperSnakeCase.MatchString(value) {
+					findings = append(findings, Finding{
+						SchemaPath: schemautil.AppendPath(path, "enum"),
+						Message:    fmt.Sprintf("enum value %q is not UPPER_SNAKE case", value),
+					})
+				}
+			}
+
+			return findings
+		},
+	}
+}
+
+// NoAdditionalProperties returns a rule that forbids "additionalProperties"
+// from being true.
+//
+// This is mostly useful for schemas of requests, where unknown properties are
+// usually a mistake on the part of the client.
+func NoAdditionalProperties() Rule {
+	return Rule{
+		Name:     "no-additional-properties",
+		Severity: SeverityWarning,
+		Check: func(path []string, s jtd.Schema) []Finding {
+			if !s.AdditionalProperties {
+				return nil
+			}
+
+			return []Finding{{
+				SchemaPath: schemautil.AppendPath(path, "additionalProperties"),
+				Message:    "schema allows additional properties",
+			}}
+		},
+	}
+}
+
+// DiscriminatorName returns a rule that requires every discriminator to be
+// named name.
+func DiscriminatorName(name string) Rule {
+	return Rule{
+		Name:     "discriminator-name",
+		Severity: SeverityWarning,
+		Check: func(path []string, s jtd.Schema) []Finding {
+			if s.Discriminator == "" || s.Discriminator == name {
+				return nil
+			}
+
+			return []Finding{{
+				SchemaPath: schemautil.AppendPath(path, "discriminator"),
+				Message:    fmt.Sprintf("discriminator is %q, not %q", s.Discriminator, name),
+			}}
+		},
+	}
+}
@@ -0,0 +1,121 @@
+package jtd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MetadataDescription is the metadata key of a human-readable description
+	// of a schema, which tools such as code and documentation generators use in
+	// their output.
+	MetadataDescription = "description"
+
+	// MetadataDeprecated is the metadata key that marks a schema as deprecated,
+	// when its value is true.
+	MetadataDeprecated = "deprecated"
+
+	// MetadataGoType is the metadata key of the Go type that code generators
+	// should use for a schema, instead of the one they'd pick on their own.
+	MetadataGoType = "goType"
+
+	// MetadataExample is the metadata key of an example instance of a schema.
+	MetadataExample = "example"
+)
+
+// ErrInvalidMetadata indicates that the metadata of a schema is not valid
+// against a metadata schema. See Schema.ValidateMetadata.
+var ErrInvalidMetadata = errors.New("jtd: metadata not valid against metadata schema")
+
+// Description returns the "description" metadata of s, or "" if it has none or
+// it's not a string.
+func (s Schema) Description() string {
+	d, _ := s.Metadata[MetadataDescription].(string)
+	return d
+}
+
+// Deprecated returns whether the "deprecated" metadata of s is true.
+func (s Schema) Deprecated() bool {
+	d, _ := s.Metadata[MetadataDeprecated].(bool)
+	return d
+}
+
+// GoType returns the "goType" metadata of s, or "" if it has none or it's not a
+// string.
+func (s Schema) GoType() string {
+	t, _ := s.Metadata[MetadataGoType].(string)
+	return t
+}
+
+// Example returns the "example" metadata of s, and whether s has one.
+func (s Schema) Example() (interface{}, bool) {
+	v, ok := s.Metadata[MetadataExample]
+	return v, ok
+}
+
+// Default returns the "default" metadata of s, and whether s has one. See
+// ApplyDefaults.
+func (s Schema) Default() (interface{}, bool) {
+	v, ok := s.Metadata[MetadataDefault]
+	return v, ok
+}
+
+// ValidateMetadata returns an error if the metadata of s, or of any of its
+// subschemas and definitions, is not valid against metaSchema. Schemas without
+// metadata are not checked. This lets an organization enforce conventions for
+// metadata, such as "description" being a string, or "deprecated" being a
+// boolean.
+//
+// ValidateMetadata does not check that s is itself valid; use Validate for
+// that. It returns the same errors as Validate if metaSchema is not a valid
+// root schema, and an error wrapping ErrInvalidMetadata that points at the
+// first invalid metadata value otherwise. For example, this metadata schema
+// only allows the keys for which Schema has accessors:
+//
+//	{
+//	  "optionalProperties": {
+//	    "description": { "type": "string" },
+//	    "deprecated": { "type": "boolean" },
+//	    "goType": { "type": "string" },
+//	    "example": {},
+//	    "default": {}
+//	  }
+//	}
+func (s Schema) ValidateMetadata(metaSchema Schema) error {
+	compiled, err := Compile(metaSchema)
+	if err != nil {
+		return err
+	}
+
+	return Walk(s, func(path []string, s *Schema) error {
+		if s.Metadata == nil {
+			return nil
+		}
+
+		errs, err := compiled.Validate(s.Metadata, WithMaxErrors(1))
+		if err != nil {
+			return err
+		}
+
+		if len(errs) != 0 {
+			path = append(append(path, "metadata"), errs[0].InstancePath...)
+			return fmt.Errorf("%w: at %q", ErrInvalidMetadata, jsonPointer(path))
+		}
+
+		return nil
+	})
+}
+
+var pointerTokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonPointer returns the JSON Pointer made up of the given tokens.
+func jsonPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(pointerTokenEscaper.Replace(token))
+	}
+
+	return b.String()
+}
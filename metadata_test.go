@@ -0,0 +1,119 @@
+package jtd_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataAccessors(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"type": "string",
+		"metadata": {
+			"description": "A name.",
+			"deprecated": true,
+			"goType": "Name",
+			"example": "Jane",
+			"default": ""
+		}
+	}`), &schema))
+
+	assert.Equal(t, "A name.", schema.Description())
+	assert.True(t, schema.Deprecated())
+	assert.Equal(t, "Name", schema.GoType())
+
+	example, ok := schema.Example()
+	assert.True(t, ok)
+	assert.Equal(t, "Jane", example)
+
+	def, ok := schema.Default()
+	assert.True(t, ok)
+	assert.Equal(t, "", def)
+
+	// Missing keys, or values of the wrong type, are ignored.
+	schema = jtd.Schema{Metadata: map[string]interface{}{
+		"description": 1.0,
+		"deprecated":  "yes",
+		"goType":      nil,
+	}}
+
+	assert.Equal(t, "", schema.Description())
+	assert.False(t, schema.Deprecated())
+	assert.Equal(t, "", schema.GoType())
+
+	_, ok = schema.Example()
+	assert.False(t, ok)
+
+	_, ok = schema.Default()
+	assert.False(t, ok)
+
+	_, ok = jtd.Schema{}.Example()
+	assert.False(t, ok)
+}
+
+func TestValidateMetadata(t *testing.T) {
+	metaSchema := jtd.Schema{
+		OptionalProperties: map[string]jtd.Schema{
+			"description": {Type: jtd.TypeString},
+			"deprecated":  {Type: jtd.TypeBoolean},
+		},
+	}
+
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"metadata": { "description": "A user." },
+		"definitions": {
+			"name": { "type": "string", "metadata": { "deprecated": true } }
+		},
+		"properties": {
+			"name": { "ref": "name" },
+			"tags": { "elements": { "type": "string" } }
+		}
+	}`), &schema))
+	assert.NoError(t, schema.ValidateMetadata(metaSchema))
+
+	schema.Properties["tags"].Elements.Metadata = map[string]interface{}{"deprecated": "yes"}
+	err := schema.ValidateMetadata(metaSchema)
+	assert.True(t, errors.Is(err, jtd.ErrInvalidMetadata))
+	assert.EqualError(t, err, `jtd: metadata not valid against metadata schema: at "/properties/tags/elements/metadata/deprecated"`)
+
+	schema.Properties["tags"].Elements.Metadata = nil
+	schema.Definitions["a/b"] = jtd.Schema{Type: jtd.TypeString, Metadata: map[string]interface{}{"goType": "Name"}}
+	err = schema.ValidateMetadata(metaSchema)
+	assert.EqualError(t, err, `jtd: metadata not valid against metadata schema: at "/definitions/a~1b/metadata/goType"`)
+
+	// Metadata is only checked when asked for.
+	assert.NoError(t, schema.Validate())
+
+	a := "a"
+	err = schema.ValidateMetadata(jtd.Schema{Ref: &a})
+	assert.Equal(t, jtd.ErrNoSuchDefinition, err)
+}
+
+func ExampleSchema_ValidateMetadata() {
+	// Require descriptions to be strings.
+	metaSchema := jtd.Schema{
+		OptionalProperties: map[string]jtd.Schema{
+			"description": {Type: jtd.TypeString},
+		},
+		AdditionalProperties: true,
+	}
+
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"name": {
+				Type:     jtd.TypeString,
+				Metadata: map[string]interface{}{"description": true},
+			},
+		},
+	}
+
+	fmt.Println(schema.ValidateMetadata(metaSchema))
+	// Output:
+	// jtd: metadata not valid against metadata schema: at "/properties/name/metadata/description"
+}
@@ -0,0 +1,241 @@
+// Package openapi converts JSON Typedef schemas into OpenAPI 3.1 component
+// schemas.
+//
+// OpenAPI 3.1 uses JSON Schema 2020-12, which can express everything JSON
+// Typedef can, with one exception: timestamps are converted into strings with
+// a "date-time" format, which JSON Schema 2020-12 treats as an annotation
+// rather than checking it, unless a validator opts in to format assertions.
+// Definitions are kept under "$defs" of the component they belong to, and the
+// discriminator form is converted into a "oneOf" with an OpenAPI discriminator
+// object.
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// ErrInvalidName indicates that a name given to Components is not allowed as
+// the name of an OpenAPI component.
+var ErrInvalidName = errors.New("openapi: invalid component name")
+
+var componentName = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// Components returns an OpenAPI 3.1 "components.schemas" object for schemas,
+// keyed by component name.
+//
+// Each schema must be a valid root schema. The result consists only of maps,
+// slices, strings, numbers, and booleans, so it can be marshaled into the rest
+// of an OpenAPI document with encoding/json or any YAML library.
+func Components(schemas map[string]jtd.Schema) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(schemas))
+	for name, schema := range schemas {
+		if !componentName.MatchString(name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+		}
+
+		if err := schema.Validate(); err != nil {
+			return nil, fmt.Errorf("openapi: %s: %w", name, err)
+		}
+
+		c := converter{component: Ref(name)["$ref"].(string)}
+		out[name] = c.convert([]string{}, schema)
+	}
+
+	return out, nil
+}
+
+// Ref returns a reference object that points to the component schema called
+// name, for use in path operations, e.g. as the "schema" of a media type.
+func Ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + pointerToken(name)}
+}
+
+// Content returns a "content" object for a request or response body whose JSON
+// payload is described by the component schema called name.
+func Content(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": Ref(name)},
+	}
+}
+
+type converter struct {
+	// component is the URI reference of the component being converted.
+	component string
+}
+
+// convert returns the JSON Schema for s. path is where that JSON Schema will be
+// found within the component, as JSON Pointer tokens.
+func (c converter) convert(path []string, s jtd.Schema) map[string]interface{} {
+	out := map[string]interface{}{}
+	if description := s.Description(); description != "" {
+		out["description"] = description
+	}
+
+	if s.Definitions != nil {
+		defs := map[string]interface{}{}
+		for name, def := range s.Definitions {
+			defs[name] = c.convert(schemautil.AppendPath(path, "$defs", name), def)
+		}
+
+		out["$defs"] = defs
+	}
+
+	switch s.Form() {
+	case jtd.FormEmpty:
+		return out
+	case jtd.FormRef:
+		ref := map[string]interface{}{"$ref": c.component + "/$defs/" + pointerToken(*s.Ref)}
+		if !s.Nullable {
+			for k, v := range ref {
+				out[k] = v
+			}
+
+			return out
+		}
+
+		out["anyOf"] = []interface{}{ref, map[string]interface{}{"type": "null"}}
+		return out
+	case jtd.FormType:
+		switch s.Type {
+		case jtd.TypeBoolean:
+			out["type"] = "boolean"
+		case jtd.TypeFloat32:
+			out["type"], out["format"] = "number", "float"
+		case jtd.TypeFloat64:
+			out["type"], out["format"] = "number", "double"
+		case jtd.TypeInt8:
+			setInteger(out, -128, 127)
+		case jtd.TypeUint8:
+			setInteger(out, 0, 255)
+		case jtd.TypeInt16:
+			setInteger(out, -32768, 32767)
+		case jtd.TypeUint16:
+			setInteger(out, 0, 65535)
+		case jtd.TypeInt32:
+			setInteger(out, -2147483648, 2147483647)
+			out["format"] = "int32"
+		case jtd.TypeUint32:
+			setInteger(out, 0, 4294967295)
+		case jtd.TypeString:
+			out["type"] = "string"
+		case jtd.TypeTimestamp:
+			out["type"], out["format"] = "string", "date-time"
+		}
+	case jtd.FormEnum:
+		values := make([]interface{}, 0, len(s.Enum)+1)
+		for _, value := range s.Enum {
+			values = append(values, value)
+		}
+
+		if s.Nullable {
+			values = append(values, nil)
+		}
+
+		out["type"], out["enum"] = "string", values
+	case jtd.FormElements:
+		out["type"] = "array"
+		out["items"] = c.convert(schemautil.AppendPath(path, "items"), *s.Elements)
+	case jtd.FormProperties:
+		c.object(path, out, s, nil)
+	case jtd.FormValues:
+		out["type"] = "object"
+		out["additionalProperties"] = c.convert(schemautil.AppendPath(path, "additionalProperties"), *s.Values)
+	case jtd.FormDiscriminator:
+		var oneOf []interface{}
+		mapping := map[string]interface{}{}
+		for i, tag := range schemautil.SortedKeys(s.Mapping) {
+			variantPath := schemautil.AppendPath(path, "oneOf", strconv.Itoa(i))
+
+			variant := map[string]interface{}{}
+			if description := s.Mapping[tag].Description(); description != "" {
+				variant["description"] = description
+			}
+
+			c.object(variantPath, variant, s.Mapping[tag], &tagProperty{s.Discriminator, tag})
+			oneOf = append(oneOf, variant)
+			mapping[tag] = c.component + pointer(variantPath)
+		}
+
+		if s.Nullable {
+			oneOf = append(oneOf, map[string]interface{}{"type": "null"})
+		}
+
+		out["oneOf"] = oneOf
+		out["discriminator"] = map[string]interface{}{
+			"propertyName": s.Discriminator,
+			"mapping":      mapping,
+		}
+
+		return out
+	}
+
+	if s.Nullable {
+		out["type"] = []interface{}{out["type"], "null"}
+	}
+
+	return out
+}
+
+type tagProperty struct {
+	name  string
+	value string
+}
+
+// object sets out to be a JSON Schema for objects matching s, a schema of the
+// properties form. If tag is not nil, it is required to be in the object as
+// well.
+func (c converter) object(path []string, out map[string]interface{}, s jtd.Schema, tag *tagProperty) {
+	properties := map[string]interface{}{}
+	required := []interface{}{}
+
+	if tag != nil {
+		properties[tag.name] = map[string]interface{}{"type": "string", "const": tag.value}
+		required = append(required, tag.name)
+	}
+
+	for _, name := range schemautil.SortedKeys(s.Properties) {
+		properties[name] = c.convert(schemautil.AppendPath(path, "properties", name), s.Properties[name])
+		required = append(required, name)
+	}
+
+	for _, name := range schemautil.SortedKeys(s.OptionalProperties) {
+		properties[name] = c.convert(schemautil.AppendPath(path, "properties", name), s.OptionalProperties[name])
+	}
+
+	out["type"] = "object"
+	out["properties"] = properties
+	if len(required) > 0 {
+		out["required"] = required
+	}
+
+	if !s.AdditionalProperties {
+		out["additionalProperties"] = false
+	}
+}
+
+func setInteger(out map[string]interface{}, min, max int64) {
+	out["type"], out["minimum"], out["maximum"] = "integer", min, max
+}
+
+// pointer returns path as a JSON Pointer, escaped for use in a URI fragment.
+func pointer(path []string) string {
+	var b strings.Builder
+	for _, token := range path {
+		b.WriteString("/")
+		b.WriteString(pointerToken(token))
+	}
+
+	return b.String()
+}
+
+func pointerToken(token string) string {
+	return url.PathEscape(schemautil.EscapeToken(token))
+}
@@ -0,0 +1,141 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponents(t *testing.T) {
+	var order jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"metadata": { "description": "An order." },
+		"definitions": {
+			"money": {
+				"properties": { "amount": { "type": "float64" } },
+				"additionalProperties": true
+			}
+		},
+		"properties": {
+			"id": { "type": "uint8" },
+			"at": { "type": "timestamp", "nullable": true },
+			"total": { "ref": "money" },
+			"discount": { "ref": "money", "nullable": true },
+			"status": { "enum": ["OPEN", "CLOSED"], "nullable": true },
+			"tags": { "elements": { "type": "string" } },
+			"counts": { "values": { "type": "int32" } },
+			"extra": {}
+		},
+		"optionalProperties": {
+			"event": {
+				"discriminator": "type",
+				"mapping": {
+					"paid": { "properties": { "by": { "type": "string" } } },
+					"void": { "properties": {} }
+				}
+			}
+		}
+	}`), &order))
+
+	components, err := openapi.Components(map[string]jtd.Schema{"Order": order})
+	assert.NoError(t, err)
+
+	actual, err := json.Marshal(components)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Order": {
+			"description": "An order.",
+			"$defs": {
+				"money": {
+					"type": "object",
+					"properties": { "amount": { "type": "number", "format": "double" } },
+					"required": ["amount"]
+				}
+			},
+			"type": "object",
+			"properties": {
+				"id": { "type": "integer", "minimum": 0, "maximum": 255 },
+				"at": { "type": ["string", "null"], "format": "date-time" },
+				"total": { "$ref": "#/components/schemas/Order/$defs/money" },
+				"discount": {
+					"anyOf": [
+						{ "$ref": "#/components/schemas/Order/$defs/money" },
+						{ "type": "null" }
+					]
+				},
+				"status": { "type": ["string", "null"], "enum": ["OPEN", "CLOSED", null] },
+				"tags": { "type": "array", "items": { "type": "string" } },
+				"counts": {
+					"type": "object",
+					"additionalProperties": {
+						"type": "integer",
+						"format": "int32",
+						"minimum": -2147483648,
+						"maximum": 2147483647
+					}
+				},
+				"extra": {},
+				"event": {
+					"oneOf": [
+						{
+							"type": "object",
+							"properties": {
+								"type": { "type": "string", "const": "paid" },
+								"by": { "type": "string" }
+							},
+							"required": ["type", "by"],
+							"additionalProperties": false
+						},
+						{
+							"type": "object",
+							"properties": {
+								"type": { "type": "string", "const": "void" }
+							},
+							"required": ["type"],
+							"additionalProperties": false
+						}
+					],
+					"discriminator": {
+						"propertyName": "type",
+						"mapping": {
+							"paid": "#/components/schemas/Order/properties/event/oneOf/0",
+							"void": "#/components/schemas/Order/properties/event/oneOf/1"
+						}
+					}
+				}
+			},
+			"required": ["at", "counts", "discount", "extra", "id", "status", "tags", "total"],
+			"additionalProperties": false
+		}
+	}`, string(actual))
+}
+
+func TestComponentsErrors(t *testing.T) {
+	_, err := openapi.Components(map[string]jtd.Schema{"not valid": jtd.Schema{}})
+	assert.True(t, errors.Is(err, openapi.ErrInvalidName))
+
+	_, err = openapi.Components(map[string]jtd.Schema{"Bad": jtd.Schema{Type: "nonsense"}})
+	assert.True(t, errors.Is(err, jtd.ErrInvalidType))
+}
+
+func TestRef(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{"$ref": "#/components/schemas/Order"}, openapi.Ref("Order"))
+}
+
+func ExampleContent() {
+	operation := map[string]interface{}{
+		"requestBody": map[string]interface{}{
+			"content": openapi.Content("Order"),
+		},
+	}
+
+	out, _ := json.Marshal(operation)
+	fmt.Println(string(out))
+	// Output:
+	// {"requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/Order"}}}}}
+}
@@ -0,0 +1,272 @@
+package jtd
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// The functions in this file rewrite a valid root schema into another valid
+// root schema that accepts exactly the same instances. The SchemaPath of the
+// errors Validate returns for the rewritten schema may differ, and because they
+// change how many refs are followed, they may change whether a given
+// WithMaxDepth is exceeded.
+
+// PruneUnusedDefinitions returns a copy of s without the definitions that
+// cannot be reached from s by following refs.
+func PruneUnusedDefinitions(s Schema) Schema {
+	if s.Definitions == nil {
+		return s
+	}
+
+	reachable := map[string]struct{}{}
+	queue := schemaRefs(s)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		if _, ok := reachable[name]; ok {
+			continue
+		}
+
+		reachable[name] = struct{}{}
+		queue = append(queue, schemaRefs(s.Definitions[name])...)
+	}
+
+	definitions := make(map[string]Schema, len(reachable))
+	for name := range reachable {
+		definitions[name] = s.Definitions[name]
+	}
+
+	s.Definitions = definitions
+	return s
+}
+
+// InlineRefs returns a copy of s where every ref to a definition that is used
+// exactly once, and that cannot reach itself by following refs, is replaced by
+// the contents of that definition. Inlined definitions are removed.
+func InlineRefs(s Schema) Schema {
+	if s.Definitions == nil {
+		return s
+	}
+
+	uses := map[string]int{}
+	Walk(s, func(path []string, s *Schema) error {
+		if s.Ref != nil {
+			uses[*s.Ref]++
+		}
+
+		return nil
+	})
+
+	inline := map[string]bool{}
+	for name := range s.Definitions {
+		if uses[name] == 1 && !definitionReachesItself(s, name) {
+			inline[name] = true
+		}
+	}
+
+	if len(inline) == 0 {
+		return s
+	}
+
+	definitions := s.Definitions
+	out, _ := Transform(s, func(path []string, s *Schema) error {
+		for s.Ref != nil && inline[*s.Ref] {
+			def := definitions[*s.Ref]
+			def.Nullable = def.Nullable || s.Nullable
+			def.Metadata = mergeMetadata(def.Metadata, s.Metadata)
+			def.Definitions = s.Definitions
+			*s = def
+		}
+
+		return nil
+	})
+
+	for name := range inline {
+		delete(out.Definitions, name)
+	}
+
+	return out
+}
+
+// ExtractDefinitions returns a copy of s where subschemas that appear more than
+// once are hoisted into definitions, and replaced with refs to them.
+//
+// Subschemas that are identical to an existing definition are replaced with a
+// ref to that definition. Only subschemas of the enum, elements, properties,
+// values, and discriminator forms are extracted, and the values of a "mapping"
+// are never extracted. New definitions are named "extracted0", "extracted1",
+// and so on, skipping names already in use.
+func ExtractDefinitions(s Schema) Schema {
+	next := 0
+	for {
+		existing := map[string]string{}
+		for name, def := range s.Definitions {
+			existing[schemaKey(def)] = name
+		}
+
+		counts := map[string]int{}
+		bodies := map[string]Schema{}
+		Walk(s, func(path []string, s *Schema) error {
+			if isExtractable(path, *s) {
+				key := schemaKey(*s)
+				counts[key]++
+				bodies[key] = *s
+			}
+
+			return nil
+		})
+
+		var keys []string
+		for key, count := range counts {
+			if _, ok := existing[key]; ok || count > 1 {
+				keys = append(keys, key)
+			}
+		}
+
+		if len(keys) == 0 {
+			return s
+		}
+
+		// Extract the largest subschema first, so that subschemas nested within
+		// it get extracted out of the new definition rather than once per copy.
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) > len(keys[j])
+			}
+
+			return keys[i] < keys[j]
+		})
+
+		key := keys[0]
+		name, ok := existing[key]
+		if !ok {
+			for {
+				name = "extracted" + strconv.Itoa(next)
+				next++
+
+				if _, ok := s.Definitions[name]; !ok {
+					break
+				}
+			}
+		}
+
+		s, _ = Transform(s, func(path []string, s *Schema) error {
+			if isExtractable(path, *s) && schemaKey(*s) == key {
+				*s = Schema{Ref: &name}
+				return SkipSubtree
+			}
+
+			return nil
+		})
+
+		if !ok {
+			if s.Definitions == nil {
+				s.Definitions = map[string]Schema{}
+			}
+
+			s.Definitions[name] = bodies[key]
+		}
+	}
+}
+
+// schemaRefs returns the refs that appear in s, without looking into its
+// definitions.
+func schemaRefs(s Schema) []string {
+	var refs []string
+	Walk(s, func(path []string, s *Schema) error {
+		if len(path) == 2 && path[0] == "definitions" {
+			return SkipSubtree
+		}
+
+		if s.Ref != nil {
+			refs = append(refs, *s.Ref)
+		}
+
+		return nil
+	})
+
+	return refs
+}
+
+func definitionReachesItself(root Schema, name string) bool {
+	seen := map[string]struct{}{}
+	queue := schemaRefs(root.Definitions[name])
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		if next == name {
+			return true
+		}
+
+		if _, ok := seen[next]; ok {
+			continue
+		}
+
+		seen[next] = struct{}{}
+		queue = append(queue, schemaRefs(root.Definitions[next])...)
+	}
+
+	return false
+}
+
+func mergeMetadata(base, override map[string]interface{}) map[string]interface{} {
+	if len(override) == 0 {
+		return base
+	}
+
+	if len(base) == 0 {
+		return override
+	}
+
+	out := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		out[k] = v
+	}
+
+	for k, v := range override {
+		out[k] = v
+	}
+
+	return out
+}
+
+func isExtractable(path []string, s Schema) bool {
+	// The root, definitions, and mapping values are never extracted.
+	if len(path) == 0 || (len(path) == 2 && path[0] == "definitions") || lastKeyword(path) == "mapping" {
+		return false
+	}
+
+	switch s.Form() {
+	case FormEnum, FormElements, FormProperties, FormValues, FormDiscriminator:
+		return true
+	default:
+		return false
+	}
+}
+
+// lastKeyword returns the last keyword in path, skipping over the definition,
+// property, and mapping names that follow keywords.
+func lastKeyword(path []string) string {
+	keyword := ""
+	for i := 0; i < len(path); i++ {
+		keyword = path[i]
+		switch keyword {
+		case "definitions", "properties", "optionalProperties", "mapping":
+			i++
+		}
+	}
+
+	return keyword
+}
+
+// schemaKey returns a string that is equal for two schemas if and only if they
+// are structurally identical.
+func schemaKey(s Schema) string {
+	// Schemas consist only of types that encoding/json can marshal, and it sorts
+	// map keys, so this always succeeds and is deterministic.
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,145 @@
+package jtd_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const optimizeSchema = `{
+	"definitions": {
+		"unused": { "type": "string" },
+		"usedOnce": {
+			"properties": {
+				"name": { "type": "string" }
+			}
+		},
+		"usedTwice": { "enum": ["A", "B"] },
+		"tree": {
+			"properties": {
+				"children": { "elements": { "ref": "tree" } }
+			}
+		}
+	},
+	"properties": {
+		"a": { "ref": "usedOnce", "nullable": true },
+		"b": { "ref": "usedTwice" },
+		"c": { "ref": "usedTwice" },
+		"d": { "ref": "tree" },
+		"e": { "values": { "elements": { "type": "uint8" } } },
+		"f": { "values": { "elements": { "type": "uint8" } } },
+		"g": {
+			"discriminator": "type",
+			"mapping": {
+				"x": { "properties": { "v": { "elements": { "type": "uint8" } } } },
+				"y": { "properties": { "v": { "elements": { "type": "uint8" } } } }
+			}
+		}
+	}
+}`
+
+const optimizeInstances = `[
+	{ "a": null, "b": "A", "c": "B", "d": { "children": [] }, "e": {}, "f": {}, "g": { "type": "x", "v": [] } },
+	{ "a": { "name": "x" }, "b": "A", "c": "B", "d": { "children": [{ "children": [] }] }, "e": { "x": [1, 2] }, "f": {}, "g": { "type": "y", "v": [3] } },
+	{ "a": { "name": 1 }, "b": "A", "c": "B", "d": { "children": [] }, "e": {}, "f": {}, "g": { "type": "x", "v": [] } },
+	{ "a": null, "b": "C", "c": "B", "d": { "children": [] }, "e": {}, "f": {}, "g": { "type": "x", "v": [] } },
+	{ "a": null, "b": "A", "c": "B", "d": { "children": [{ "children": 1 }] }, "e": {}, "f": {}, "g": { "type": "x", "v": [] } },
+	{ "a": null, "b": "A", "c": "B", "d": { "children": [] }, "e": { "x": [256] }, "f": {}, "g": { "type": "x", "v": [] } },
+	{ "a": null, "b": "A", "c": "B", "d": { "children": [] }, "e": {}, "f": {}, "g": { "type": "y", "v": [-1] } }
+]`
+
+func loadOptimizeFixtures(t *testing.T) (jtd.Schema, []interface{}) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(optimizeSchema), &schema))
+	assert.NoError(t, schema.Validate())
+
+	var instances []interface{}
+	assert.NoError(t, json.Unmarshal([]byte(optimizeInstances), &instances))
+
+	return schema, instances
+}
+
+func assertEquivalent(t *testing.T, a, b jtd.Schema, instances []interface{}) {
+	assert.NoError(t, b.Validate())
+
+	for i, instance := range instances {
+		errsA, err := jtd.Validate(a, instance)
+		assert.NoError(t, err)
+
+		errsB, err := jtd.Validate(b, instance)
+		assert.NoError(t, err)
+
+		assert.Equal(t, len(errsA) == 0, len(errsB) == 0, "instance %d", i)
+	}
+}
+
+func TestPruneUnusedDefinitions(t *testing.T) {
+	schema, instances := loadOptimizeFixtures(t)
+
+	out := jtd.PruneUnusedDefinitions(schema)
+	assert.NotContains(t, out.Definitions, "unused")
+	assert.Len(t, out.Definitions, 3)
+	assert.Len(t, schema.Definitions, 4)
+	assertEquivalent(t, schema, out, instances)
+}
+
+func TestInlineRefs(t *testing.T) {
+	schema, instances := loadOptimizeFixtures(t)
+
+	out := jtd.InlineRefs(schema)
+	assert.NotContains(t, out.Definitions, "usedOnce")
+	assert.Contains(t, out.Definitions, "usedTwice")
+	assert.Contains(t, out.Definitions, "tree")
+	assert.Nil(t, out.Properties["a"].Ref)
+	assert.True(t, out.Properties["a"].Nullable)
+	assertEquivalent(t, schema, out, instances)
+}
+
+func TestInlineRefsRoot(t *testing.T) {
+	foo := "foo"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"foo": jtd.Schema{Type: jtd.TypeString},
+		},
+		Ref: &foo,
+	}
+
+	out := jtd.InlineRefs(schema)
+	assert.Equal(t, jtd.Schema{
+		Definitions: map[string]jtd.Schema{},
+		Type:        jtd.TypeString,
+	}, out)
+}
+
+func TestExtractDefinitions(t *testing.T) {
+	schema, instances := loadOptimizeFixtures(t)
+
+	out := jtd.ExtractDefinitions(schema)
+
+	// "values" of "elements" is repeated, and so is the "elements" within it
+	// (including in the mapping values, which are themselves never extracted).
+	assert.Equal(t, "extracted0", *out.Properties["e"].Ref)
+	assert.Equal(t, "extracted0", *out.Properties["f"].Ref)
+	assert.Equal(t, "extracted1", *out.Definitions["extracted0"].Values.Ref)
+	assert.Equal(t, "extracted1", *out.Properties["g"].Mapping["x"].Properties["v"].Ref)
+	assert.Nil(t, out.Properties["g"].Mapping["x"].Ref)
+
+	// Refs to existing definitions are kept as-is.
+	assert.Equal(t, "usedTwice", *out.Properties["b"].Ref)
+	assertEquivalent(t, schema, out, instances)
+}
+
+func TestExtractDefinitionsExisting(t *testing.T) {
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"strings": jtd.Schema{Elements: &jtd.Schema{Type: jtd.TypeString}},
+		},
+		Values: &jtd.Schema{Elements: &jtd.Schema{Type: jtd.TypeString}},
+	}
+
+	out := jtd.ExtractDefinitions(schema)
+	assert.Equal(t, "strings", *out.Values.Ref)
+	assert.Len(t, out.Definitions, 1)
+}
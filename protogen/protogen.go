@@ -0,0 +1,690 @@
+// Package protogen exports JSON Typedef schemas as Protocol Buffers (proto3)
+// definitions.
+//
+// Schemas are mapped to proto3 as follows:
+//
+//	empty            google.protobuf.Value
+//	boolean          bool
+//	float32          float
+//	float64          double
+//	int8 ... int32   int32
+//	uint8 ... uint32 uint32
+//	string           string
+//	timestamp        google.protobuf.Timestamp
+//	enum             enum, with an extra zero value named *_UNSPECIFIED
+//	elements         repeated field
+//	properties       message
+//	values           map<string, T>
+//	discriminator    message with a oneof of messages, one per mapping value
+//	ref              the message or enum of the definition, or else the
+//	                 definition's type inlined
+//
+// Definitions of the properties, discriminator, and enum forms become
+// top-level messages and enums. Properties, enums, and discriminators that
+// appear inline become nested messages and enums.
+//
+// Enum values are prefixed with the name of their enum, as is conventional in
+// proto3. Nullable and optional fields of scalar and enum types are marked
+// "optional", so that their presence is tracked. Field numbers are assigned in order
+// (required properties, then optional properties, sorted by name), unless the
+// property's schema has a "protoFieldNumber" in its metadata. The package is
+// taken from a "protoPackage" in the metadata of the root schema.
+//
+// Distinct names in a schema can map to the same proto3 name, such as the
+// properties "fooBar" and "foo_bar". When they do, the ones that come later are
+// given a numeric suffix, which is reported as a Loss.
+//
+// Some JSON Typedef schemas cannot be represented exactly in proto3. Generate
+// makes a best effort for these, and reports each one as a Loss.
+package protogen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// MetadataFieldNumber is the metadata key of a property or mapping value
+// schema that sets the field number it is given.
+const MetadataFieldNumber = "protoFieldNumber"
+
+// MetadataPackage is the metadata key of a root schema that sets the package
+// of the generated proto file.
+const MetadataPackage = "protoPackage"
+
+// ErrInvalidFieldNumber indicates that a schema has a "protoFieldNumber" that
+// is not a valid proto field number, or that is already used by another field
+// of the same message.
+var ErrInvalidFieldNumber = errors.New("protogen: invalid field number")
+
+// Loss describes a part of a schema that proto3 cannot represent exactly.
+type Loss struct {
+	// Path to the part of the schema that could not be represented exactly,
+	// using the same tokens as the SchemaPath of jtd.ValidateError.
+	SchemaPath []string
+
+	// A human-readable description of what is lost.
+	Message string
+}
+
+// Generate writes a proto3 file for schema to w. name is the name of the
+// message generated for the root schema.
+//
+// The root schema gets a message of its own unless it is of the empty form and
+// has definitions. If it is not of the properties or discriminator form, the
+// message wraps it in a field called "value".
+func Generate(w io.Writer, schema jtd.Schema, name string) ([]Loss, error) {
+	g := generator{
+		root:     schema,
+		names:    map[string]string{},
+		topLevel: map[string]bool{},
+		imports:  map[string]bool{},
+	}
+
+	// The root message is named first, so that it keeps the name it was given.
+	hasRoot := schema.Form() != jtd.FormEmpty || len(schema.Definitions) == 0
+	if hasRoot {
+		name = g.uniqueName(nil, g.topLevel, typeName(name))
+	}
+
+	for _, def := range schemautil.SortedKeys(schema.Definitions) {
+		switch schema.Definitions[def].Form() {
+		case jtd.FormProperties, jtd.FormDiscriminator, jtd.FormEnum:
+			g.names[def] = g.uniqueName([]string{"definitions", def}, g.topLevel, typeName(def))
+		}
+	}
+
+	var decls []decl
+	if hasRoot {
+		d, err := g.declare([]string{}, name, schema)
+		if err != nil {
+			return nil, err
+		}
+
+		decls = append(decls, d)
+	}
+
+	for _, name := range schemautil.SortedKeys(schema.Definitions) {
+		if _, ok := g.names[name]; !ok {
+			continue
+		}
+
+		d, err := g.declare([]string{"definitions", name}, g.names[name], schema.Definitions[name])
+		if err != nil {
+			return nil, err
+		}
+
+		decls = append(decls, d)
+	}
+
+	var b strings.Builder
+	b.WriteString("syntax = \"proto3\";\n")
+
+	if pkg, ok := schema.Metadata[MetadataPackage].(string); ok {
+		fmt.Fprintf(&b, "\npackage %s;\n", pkg)
+	}
+
+	if len(g.imports) > 0 {
+		b.WriteString("\n")
+		for _, imp := range sortedStrings(g.imports) {
+			fmt.Fprintf(&b, "import %q;\n", imp)
+		}
+	}
+
+	for _, d := range decls {
+		b.WriteString("\n")
+		d.render(&b, "")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return nil, err
+	}
+
+	return g.losses, nil
+}
+
+type generator struct {
+	root jtd.Schema
+
+	// names maps the definitions that get top-level messages or enums to the
+	// names of those messages or enums.
+	names map[string]string
+
+	// topLevel is the set of names of top-level messages and enums. Nested
+	// declarations may not use them either, since they would shadow the
+	// top-level ones in refs from within the message they are nested in.
+	topLevel map[string]bool
+
+	// expanding is the set of definitions currently being inlined.
+	expanding []string
+
+	imports map[string]bool
+	losses  []Loss
+}
+
+func (g *generator) lose(path []string, format string, args ...interface{}) {
+	g.losses = append(g.losses, Loss{SchemaPath: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// uniqueName returns name, or if it is in used, name followed by the smallest
+// number that makes it unique, which is reported as a Loss at path. The result
+// is added to used.
+func (g *generator) uniqueName(path []string, used map[string]bool, name string) string {
+	out := name
+	for i := 2; used[out]; i++ {
+		out = fmt.Sprintf("%s%d", name, i)
+	}
+
+	used[out] = true
+	if out != name {
+		g.lose(path, "%s is renamed to %s, as another name maps to %s too", name, out, name)
+	}
+
+	return out
+}
+
+// uniqueFieldName is like uniqueName, for a field of m. Fields must also have
+// distinct JSON names, which proto3 derives from their names.
+func (g *generator) uniqueFieldName(path []string, m *message, name string) string {
+	out := name
+	for i := 2; m.jsonNames[lowerCamelCase(out)]; i++ {
+		out = fmt.Sprintf("%s%d", name, i)
+	}
+
+	m.jsonNames[lowerCamelCase(out)] = true
+	if out != name {
+		g.lose(path, "%s is renamed to %s, as another name maps to %s too", name, out, name)
+	}
+
+	return out
+}
+
+// declare returns a top-level or nested declaration called name for s.
+func (g *generator) declare(path []string, name string, s jtd.Schema) (decl, error) {
+	switch s.Form() {
+	case jtd.FormEnum:
+		return g.enum(path, name, s), nil
+	case jtd.FormProperties:
+		return g.message(path, name, s)
+	case jtd.FormDiscriminator:
+		return g.discriminator(path, name, s)
+	default:
+		m := g.newMessage(name)
+		f, err := g.field(path, m, "value", s, false)
+		if err != nil {
+			return nil, err
+		}
+
+		f.number = 1
+		m.fields = append(m.fields, f)
+		return m, nil
+	}
+}
+
+func (g *generator) enum(path []string, name string, s jtd.Schema) *enum {
+	prefix := constantName(name) + "_"
+	e := &enum{name: name, values: []string{prefix + "UNSPECIFIED"}}
+	used := map[string]bool{prefix + "UNSPECIFIED": true}
+	for _, value := range s.Enum {
+		constant := constantName(value)
+		if !strings.EqualFold(strings.Replace(constant, "_", "", -1), strings.Replace(value, "_", "", -1)) {
+			g.lose(schemautil.AppendPath(path, "enum"), "enum value %q is renamed to %s", value, prefix+constant)
+		}
+
+		e.values = append(e.values, g.uniqueName(schemautil.AppendPath(path, "enum"), used, prefix+constant))
+	}
+
+	return e
+}
+
+func (g *generator) message(path []string, name string, s jtd.Schema) (*message, error) {
+	m := g.newMessage(name)
+
+	for _, required := range []bool{true, false} {
+		keyword, properties := "properties", s.Properties
+		if !required {
+			keyword, properties = "optionalProperties", s.OptionalProperties
+		}
+
+		for _, key := range schemautil.SortedKeys(properties) {
+			f, err := g.field(schemautil.AppendPath(path, keyword, key), m, key, properties[key], !required)
+			if err != nil {
+				return nil, err
+			}
+
+			f.name = g.uniqueFieldName(schemautil.AppendPath(path, keyword, key), m, f.name)
+
+			if f.number, err = fieldNumber(properties[key]); err != nil {
+				return nil, fmt.Errorf("%w: %v", err, schemautil.AppendPath(path, keyword, key))
+			}
+
+			m.fields = append(m.fields, f)
+		}
+	}
+
+	if s.AdditionalProperties {
+		g.lose(schemautil.AppendPath(path, "additionalProperties"), "additional properties are dropped")
+	}
+
+	return m, m.numberFields()
+}
+
+func (g *generator) discriminator(path []string, name string, s jtd.Schema) (*message, error) {
+	m := g.newMessage(name)
+	m.oneof = fieldName(s.Discriminator)
+	m.jsonNames[lowerCamelCase(m.oneof)] = true
+
+	for _, tag := range schemautil.SortedKeys(s.Mapping) {
+		variantPath := schemautil.AppendPath(path, "mapping", tag)
+		variant, err := g.message(variantPath, g.uniqueName(variantPath, m.typeNames, typeName(tag)), s.Mapping[tag])
+		if err != nil {
+			return nil, err
+		}
+
+		number, err := fieldNumber(s.Mapping[tag])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", err, schemautil.AppendPath(path, "mapping", tag))
+		}
+
+		m.nested = append(m.nested, variant)
+		m.fields = append(m.fields, &field{typ: variant.name, name: g.uniqueFieldName(variantPath, m, fieldName(tag)), jsonName: tag, number: number})
+	}
+
+	return m, m.numberFields()
+}
+
+// field returns a field called key for s, adding any nested declarations it
+// needs to m.
+func (g *generator) field(path []string, m *message, key string, s jtd.Schema, optional bool) (*field, error) {
+	f := &field{name: fieldName(key), jsonName: key}
+
+	switch s.Form() {
+	case jtd.FormEmpty:
+		f.typ = g.value()
+		return f, nil
+	case jtd.FormRef:
+		if name, ok := g.names[*s.Ref]; ok {
+			f.typ = name
+			if g.root.Definitions[*s.Ref].Form() == jtd.FormEnum {
+				f.optional = optional || s.Nullable
+			}
+
+			return f, nil
+		}
+
+		for _, expanding := range g.expanding {
+			if expanding == *s.Ref {
+				g.lose(path, "recursive definition %q is represented as google.protobuf.Value", *s.Ref)
+				f.typ = g.value()
+				return f, nil
+			}
+		}
+
+		g.expanding = append(g.expanding, *s.Ref)
+		defer func() { g.expanding = g.expanding[:len(g.expanding)-1] }()
+
+		def := g.root.Definitions[*s.Ref]
+		def.Nullable = def.Nullable || s.Nullable
+		return g.field(path, m, key, def, optional)
+	case jtd.FormType:
+		switch s.Type {
+		case jtd.TypeBoolean:
+			f.typ = "bool"
+		case jtd.TypeFloat32:
+			f.typ = "float"
+		case jtd.TypeFloat64:
+			f.typ = "double"
+		case jtd.TypeInt8, jtd.TypeInt16, jtd.TypeInt32:
+			f.typ = "int32"
+		case jtd.TypeUint8, jtd.TypeUint16, jtd.TypeUint32:
+			f.typ = "uint32"
+		case jtd.TypeString:
+			f.typ = "string"
+		case jtd.TypeTimestamp:
+			g.imports["google/protobuf/timestamp.proto"] = true
+			f.typ = "google.protobuf.Timestamp"
+			return f, nil
+		}
+
+		f.optional = optional || s.Nullable
+		return f, nil
+	case jtd.FormEnum:
+		e := g.enum(path, g.uniqueName(path, m.typeNames, typeName(key)), s)
+		m.nested = append(m.nested, e)
+		f.typ = e.name
+		f.optional = optional || s.Nullable
+		return f, nil
+	case jtd.FormProperties:
+		nested, err := g.message(path, g.uniqueName(path, m.typeNames, typeName(key)), s)
+		if err != nil {
+			return nil, err
+		}
+
+		m.nested = append(m.nested, nested)
+		f.typ = nested.name
+		return f, nil
+	case jtd.FormDiscriminator:
+		nested, err := g.discriminator(path, g.uniqueName(path, m.typeNames, typeName(key)), s)
+		if err != nil {
+			return nil, err
+		}
+
+		m.nested = append(m.nested, nested)
+		f.typ = nested.name
+		return f, nil
+	}
+
+	// What's left are the elements and values forms, which become repeated and
+	// map fields.
+	keyword, sub := "elements", s.Elements
+	if s.Form() == jtd.FormValues {
+		keyword, sub = "values", s.Values
+	}
+
+	inner, err := g.field(schemautil.AppendPath(path, keyword), m, key, *sub, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if inner.repeated || inner.mapValue {
+		g.lose(path, "nested %s are represented as google.protobuf.Value", keyword)
+		f.typ = g.value()
+		return f, nil
+	}
+
+	if g.nullable(*sub) {
+		g.lose(schemautil.AppendPath(path, keyword), "null %s are dropped", keyword)
+	}
+
+	if s.Nullable {
+		g.lose(path, "null is not distinguished from empty %s", keyword)
+	} else if optional {
+		g.lose(path, "absent is not distinguished from empty %s", keyword)
+	}
+
+	f.typ = inner.typ
+	f.repeated = keyword == "elements"
+	f.mapValue = keyword == "values"
+	return f, nil
+}
+
+// nullable returns whether s, or any definition that s refers to through refs,
+// is nullable.
+func (g *generator) nullable(s jtd.Schema) bool {
+	for i := 0; i <= len(g.root.Definitions); i++ {
+		if s.Nullable {
+			return true
+		}
+
+		if s.Form() != jtd.FormRef {
+			return false
+		}
+
+		s = g.root.Definitions[*s.Ref]
+	}
+
+	// s is part of a cycle of refs.
+	return false
+}
+
+func (g *generator) value() string {
+	g.imports["google/protobuf/struct.proto"] = true
+	return "google.protobuf.Value"
+}
+
+// fieldNumber returns the "protoFieldNumber" of s, or zero if it has none.
+func fieldNumber(s jtd.Schema) (int, error) {
+	var n float64
+	switch v := s.Metadata[MetadataFieldNumber].(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		n = v
+	case int:
+		n = float64(v)
+	default:
+		return 0, ErrInvalidFieldNumber
+	}
+
+	if n != float64(int(n)) || !validFieldNumber(int(n)) {
+		return 0, ErrInvalidFieldNumber
+	}
+
+	return int(n), nil
+}
+
+func validFieldNumber(n int) bool {
+	// Field numbers 19000 through 19999 are reserved by the protobuf
+	// implementation.
+	return n >= 1 && n <= 536870911 && (n < 19000 || n > 19999)
+}
+
+type decl interface {
+	render(b *strings.Builder, indent string)
+}
+
+type message struct {
+	name string
+
+	// oneof, if not empty, is the name of a oneof that contains all of fields.
+	oneof string
+
+	fields []*field
+	nested []decl
+
+	// jsonNames are the default JSON names of the fields and oneof of m, and
+	// typeNames the names of its nested declarations and of all top-level
+	// declarations, so that each is unique.
+	jsonNames map[string]bool
+	typeNames map[string]bool
+}
+
+func (g *generator) newMessage(name string) *message {
+	m := &message{name: name, jsonNames: map[string]bool{}, typeNames: map[string]bool{}}
+	for n := range g.topLevel {
+		m.typeNames[n] = true
+	}
+
+	return m
+}
+
+type field struct {
+	name     string
+	jsonName string
+	typ      string
+	number   int
+	optional bool
+	repeated bool
+	mapValue bool
+}
+
+// numberFields assigns numbers to the fields of m that don't have one yet.
+func (m *message) numberFields() error {
+	used := map[int]bool{}
+	for _, f := range m.fields {
+		if f.number == 0 {
+			continue
+		}
+
+		if used[f.number] {
+			return fmt.Errorf("%w: %d is used twice in %s", ErrInvalidFieldNumber, f.number, m.name)
+		}
+
+		used[f.number] = true
+	}
+
+	next := 1
+	for _, f := range m.fields {
+		if f.number != 0 {
+			continue
+		}
+
+		for used[next] || !validFieldNumber(next) {
+			next++
+		}
+
+		f.number = next
+		used[next] = true
+	}
+
+	return nil
+}
+
+func (m *message) render(b *strings.Builder, indent string) {
+	fmt.Fprintf(b, "%smessage %s {\n", indent, m.name)
+
+	fieldIndent := indent + "  "
+	if m.oneof != "" {
+		fmt.Fprintf(b, "%soneof %s {\n", fieldIndent, m.oneof)
+		fieldIndent += "  "
+	}
+
+	for _, f := range m.fields {
+		b.WriteString(fieldIndent)
+		switch {
+		case f.optional:
+			b.WriteString("optional ")
+		case f.repeated:
+			b.WriteString("repeated ")
+		}
+
+		if f.mapValue {
+			fmt.Fprintf(b, "map<string, %s>", f.typ)
+		} else {
+			b.WriteString(f.typ)
+		}
+
+		fmt.Fprintf(b, " %s = %d", f.name, f.number)
+		if lowerCamelCase(f.name) != f.jsonName {
+			fmt.Fprintf(b, " [json_name = %q]", f.jsonName)
+		}
+
+		b.WriteString(";\n")
+	}
+
+	if m.oneof != "" {
+		fmt.Fprintf(b, "%s  }\n", indent)
+	}
+
+	for _, nested := range m.nested {
+		b.WriteString("\n")
+		nested.render(b, indent+"  ")
+	}
+
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+type enum struct {
+	name   string
+	values []string
+}
+
+func (e *enum) render(b *strings.Builder, indent string) {
+	fmt.Fprintf(b, "%senum %s {\n", indent, e.name)
+	for i, value := range e.values {
+		fmt.Fprintf(b, "%s  %s = %d;\n", indent, value, i)
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// words splits s into words, at non-alphanumeric characters and at
+// lower-to-upper case transitions.
+func words(s string) []string {
+	var out []string
+	var word []rune
+	var prev rune
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				out = append(out, string(word))
+				word = nil
+			}
+		} else {
+			if len(word) > 0 && unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) {
+				out = append(out, string(word))
+				word = nil
+			}
+
+			word = append(word, r)
+		}
+
+		prev = r
+	}
+
+	if len(word) > 0 {
+		out = append(out, string(word))
+	}
+
+	return out
+}
+
+// typeName returns s in PascalCase, for use as a message or enum name.
+func typeName(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+
+	return identifier(b.String(), "T")
+}
+
+// fieldName returns s in snake_case, for use as a field name.
+func fieldName(s string) string {
+	var parts []string
+	for _, w := range words(s) {
+		parts = append(parts, strings.ToLower(w))
+	}
+
+	return identifier(strings.Join(parts, "_"), "f_")
+}
+
+// constantName returns s in UPPER_SNAKE_CASE, for use as an enum value name.
+func constantName(s string) string {
+	return strings.ToUpper(fieldName(s))
+}
+
+// identifier returns s, with prefix added if s does not start with a letter.
+func identifier(s, prefix string) string {
+	if s == "" || !unicode.IsLetter(rune(s[0])) {
+		return prefix + s
+	}
+
+	return s
+}
+
+// lowerCamelCase returns the JSON name protobuf gives to a field by default.
+func lowerCamelCase(s string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range s {
+		if r == '_' {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func sortedStrings(set map[string]bool) []string {
+	var out []string
+	for s := range set {
+		out = append(out, s)
+	}
+
+	sort.Strings(out)
+	return out
+}
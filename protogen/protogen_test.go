@@ -0,0 +1,281 @@
+package protogen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/protogen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"metadata": { "protoPackage": "acme.orders" },
+		"definitions": {
+			"status": { "enum": ["ACTIVE", "on-hold"] },
+			"ids": { "elements": { "type": "string" } },
+			"event": {
+				"discriminator": "kind",
+				"mapping": {
+					"created": {
+						"metadata": { "protoFieldNumber": 10 },
+						"properties": { "at": { "type": "timestamp" } }
+					},
+					"deleted": { "properties": {} }
+				}
+			}
+		},
+		"properties": {
+			"orderId": { "type": "string", "metadata": { "protoFieldNumber": 2 } },
+			"line_items": {
+				"elements": {
+					"properties": { "qty": { "type": "uint16" } }
+				}
+			},
+			"status": { "ref": "status" },
+			"ids": { "ref": "ids" },
+			"prices": { "values": { "type": "float64", "nullable": true } },
+			"extra": {}
+		},
+		"optionalProperties": {
+			"note": { "type": "string", "nullable": true },
+			"tags": { "elements": { "type": "string" } }
+		},
+		"additionalProperties": true
+	}`), &schema))
+	assert.NoError(t, schema.Validate())
+
+	var b bytes.Buffer
+	losses, err := protogen.Generate(&b, schema, "order")
+	assert.NoError(t, err)
+	assert.Equal(t, `syntax = "proto3";
+
+package acme.orders;
+
+import "google/protobuf/struct.proto";
+import "google/protobuf/timestamp.proto";
+
+message Order {
+  google.protobuf.Value extra = 1;
+  repeated string ids = 3;
+  repeated LineItems line_items = 4 [json_name = "line_items"];
+  string order_id = 2;
+  map<string, double> prices = 5;
+  Status status = 6;
+  optional string note = 7;
+  repeated string tags = 8;
+
+  message LineItems {
+    uint32 qty = 1;
+  }
+}
+
+message Event {
+  oneof kind {
+    Created created = 10;
+    Deleted deleted = 1;
+  }
+
+  message Created {
+    google.protobuf.Timestamp at = 1;
+  }
+
+  message Deleted {
+  }
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+  STATUS_ON_HOLD = 2;
+}
+`, b.String())
+
+	assert.Equal(t, []protogen.Loss{
+		{SchemaPath: []string{"properties", "prices", "values"}, Message: "null values are dropped"},
+		{SchemaPath: []string{"optionalProperties", "tags"}, Message: "absent is not distinguished from empty elements"},
+		{SchemaPath: []string{"additionalProperties"}, Message: "additional properties are dropped"},
+		{SchemaPath: []string{"definitions", "status", "enum"}, Message: "enum value \"on-hold\" is renamed to STATUS_ON_HOLD"},
+	}, losses)
+}
+
+func TestGenerateRecursive(t *testing.T) {
+	tree := "tree"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"tree": jtd.Schema{Elements: &jtd.Schema{Ref: &tree}},
+		},
+		Ref: &tree,
+	}
+
+	var b bytes.Buffer
+	losses, err := protogen.Generate(&b, schema, "root")
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), "repeated google.protobuf.Value value = 1;")
+	assert.Equal(t, []protogen.Loss{{
+		SchemaPath: []string{"elements"},
+		Message:    "recursive definition \"tree\" is represented as google.protobuf.Value",
+	}}, losses)
+}
+
+func TestGenerateNameCollisions(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"order": { "properties": {} },
+			"status": { "enum": ["a-b", "a_b", "unspecified"] }
+		},
+		"properties": {
+			"fooBar": { "type": "string" },
+			"foo_bar": { "type": "string" },
+			"line-items": { "properties": {} },
+			"line_items": { "properties": {} }
+		}
+	}`), &schema))
+
+	var b bytes.Buffer
+	losses, err := protogen.Generate(&b, schema, "order")
+	assert.NoError(t, err)
+	assert.Equal(t, `syntax = "proto3";
+
+message Order {
+  string foo_bar = 1;
+  string foo_bar2 = 2 [json_name = "foo_bar"];
+  LineItems line_items = 3 [json_name = "line-items"];
+  LineItems2 line_items2 = 4 [json_name = "line_items"];
+
+  message LineItems {
+  }
+
+  message LineItems2 {
+  }
+}
+
+message Order2 {
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_A_B = 1;
+  STATUS_A_B2 = 2;
+  STATUS_UNSPECIFIED2 = 3;
+}
+`, b.String())
+
+	assert.Equal(t, []protogen.Loss{
+		{SchemaPath: []string{"definitions", "order"}, Message: "Order is renamed to Order2, as another name maps to Order too"},
+		{SchemaPath: []string{"properties", "foo_bar"}, Message: "foo_bar is renamed to foo_bar2, as another name maps to foo_bar too"},
+		{SchemaPath: []string{"properties", "line_items"}, Message: "LineItems is renamed to LineItems2, as another name maps to LineItems too"},
+		{SchemaPath: []string{"properties", "line_items"}, Message: "line_items is renamed to line_items2, as another name maps to line_items too"},
+		{SchemaPath: []string{"definitions", "status", "enum"}, Message: "enum value \"a-b\" is renamed to STATUS_A_B"},
+		{SchemaPath: []string{"definitions", "status", "enum"}, Message: "STATUS_A_B is renamed to STATUS_A_B2, as another name maps to STATUS_A_B too"},
+		{SchemaPath: []string{"definitions", "status", "enum"}, Message: "STATUS_UNSPECIFIED is renamed to STATUS_UNSPECIFIED2, as another name maps to STATUS_UNSPECIFIED too"},
+	}, losses)
+}
+
+func TestGenerateShadowedDefinition(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"address": { "properties": { "line1": { "type": "string" } } }
+		},
+		"properties": {
+			"address": { "properties": { "zip": { "type": "string" } } },
+			"home": { "ref": "address" }
+		}
+	}`), &schema))
+
+	var b bytes.Buffer
+	losses, err := protogen.Generate(&b, schema, "root")
+	assert.NoError(t, err)
+	assert.Equal(t, `syntax = "proto3";
+
+message Root {
+  Address2 address = 1;
+  Address home = 2;
+
+  message Address2 {
+    string zip = 1;
+  }
+}
+
+message Address {
+  string line1 = 1;
+}
+`, b.String())
+	assert.Equal(t, []protogen.Loss{{
+		SchemaPath: []string{"properties", "address"},
+		Message:    "Address is renamed to Address2, as another name maps to Address too",
+	}}, losses)
+}
+
+func TestGenerateNullableItems(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"point": { "properties": { "x": { "type": "float64" } } },
+			"maybePoint": { "ref": "point", "nullable": true }
+		},
+		"properties": {
+			"points": { "elements": { "ref": "point", "nullable": true } },
+			"named": { "values": { "ref": "maybePoint" } },
+			"times": { "elements": { "type": "timestamp", "nullable": true } },
+			"inline": { "elements": { "properties": {}, "nullable": true } }
+		}
+	}`), &schema))
+
+	losses, err := protogen.Generate(&bytes.Buffer{}, schema, "root")
+	assert.NoError(t, err)
+	assert.Equal(t, []protogen.Loss{
+		{SchemaPath: []string{"properties", "inline", "elements"}, Message: "null elements are dropped"},
+		{SchemaPath: []string{"properties", "named", "values"}, Message: "null values are dropped"},
+		{SchemaPath: []string{"properties", "points", "elements"}, Message: "null elements are dropped"},
+		{SchemaPath: []string{"properties", "times", "elements"}, Message: "null elements are dropped"},
+	}, losses)
+}
+
+func TestGenerateInvalidFieldNumber(t *testing.T) {
+	for _, metadata := range []interface{}{0, 19500, 1.5, "1"} {
+		schema := jtd.Schema{
+			Properties: map[string]jtd.Schema{
+				"a": jtd.Schema{Metadata: map[string]interface{}{"protoFieldNumber": metadata}},
+			},
+		}
+
+		_, err := protogen.Generate(&bytes.Buffer{}, schema, "root")
+		assert.True(t, errors.Is(err, protogen.ErrInvalidFieldNumber), "%v", metadata)
+	}
+
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"a": jtd.Schema{Metadata: map[string]interface{}{"protoFieldNumber": 1}},
+			"b": jtd.Schema{Metadata: map[string]interface{}{"protoFieldNumber": 1}},
+		},
+	}
+
+	_, err := protogen.Generate(&bytes.Buffer{}, schema, "root")
+	assert.True(t, errors.Is(err, protogen.ErrInvalidFieldNumber))
+}
+
+func ExampleGenerate() {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"name":  jtd.Schema{Type: jtd.TypeString},
+			"score": jtd.Schema{Type: jtd.TypeInt8, Nullable: true},
+		},
+	}
+
+	protogen.Generate(os.Stdout, schema, "player")
+	// Output:
+	// syntax = "proto3";
+	//
+	// message Player {
+	//   string name = 1;
+	//   optional int32 score = 2;
+	// }
+}
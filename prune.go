@@ -0,0 +1,172 @@
+package jtd
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Prune returns a copy of instance without the properties that Validate would
+// reject as additional properties, along with the paths to the properties that
+// were removed.
+//
+// Prune looks for additional properties throughout instance, following
+// elements, values, refs, and discriminator mappings. Like Validate, it allows
+// the discriminator tag in the properties of a mapping value, and keeps all
+// properties of schemas whose "additionalProperties" is true. Parts of instance
+// that don't match schema are left untouched, so that a subsequent call to
+// Validate can report them. Maps and slices are copied as necessary; instance
+// itself is never modified.
+//
+// Removed paths use the same format as ValidateError.InstancePath. They are
+// listed in the order Prune finds them, which visits elements by index and
+// object members by name.
+//
+// If instance leads Prune into one of the cycles that Schema.RefCycles reports,
+// it returns ErrUnproductiveRefCycle rather than any of the pruned instance.
+func Prune(schema Schema, instance interface{}) (interface{}, [][]string, error) {
+	state := pruneState{Root: schema, InstanceTokens: []string{}, Removed: [][]string{}}
+	out, err := prune(&state, schema, instance, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return out, state.Removed, nil
+}
+
+type pruneState struct {
+	Root           Schema
+	Refs           refStack
+	InstanceTokens []string
+	Removed        [][]string
+}
+
+func (ps *pruneState) pushRemoved(key string) {
+	path := make([]string, len(ps.InstanceTokens)+1)
+	copy(path, ps.InstanceTokens)
+	path[len(ps.InstanceTokens)] = key
+
+	ps.Removed = append(ps.Removed, path)
+}
+
+func prune(state *pruneState, schema Schema, instance interface{}, parentTag *string) (interface{}, error) {
+	if schema.Nullable && instance == nil {
+		return instance, nil
+	}
+
+	switch schema.Form() {
+	case FormRef:
+		if state.Refs.loops(*schema.Ref, len(state.InstanceTokens)) {
+			return nil, ErrUnproductiveRefCycle
+		}
+
+		state.Refs = append(state.Refs, refFrame{Name: *schema.Ref, InstanceDepth: len(state.InstanceTokens)})
+		out, err := prune(state, state.Root.Definitions[*schema.Ref], instance, nil)
+		state.Refs = state.Refs[:len(state.Refs)-1]
+
+		return out, err
+	case FormElements:
+		arr, ok := instance.([]interface{})
+		if !ok {
+			return instance, nil
+		}
+
+		out := make([]interface{}, len(arr))
+		for i, subInstance := range arr {
+			state.InstanceTokens = append(state.InstanceTokens, strconv.Itoa(i))
+			v, err := prune(state, *schema.Elements, subInstance, nil)
+			state.InstanceTokens = state.InstanceTokens[:len(state.InstanceTokens)-1]
+
+			if err != nil {
+				return nil, err
+			}
+
+			out[i] = v
+		}
+
+		return out, nil
+	case FormProperties:
+		obj, ok := instance.(map[string]interface{})
+		if !ok {
+			return instance, nil
+		}
+
+		out := make(map[string]interface{}, len(obj))
+		for _, key := range sortedInstanceKeys(obj) {
+			subSchema, ok := schema.Properties[key]
+			if !ok {
+				subSchema, ok = schema.OptionalProperties[key]
+			}
+
+			if !ok {
+				if schema.AdditionalProperties || (parentTag != nil && key == *parentTag) {
+					out[key] = obj[key]
+				} else {
+					state.pushRemoved(key)
+				}
+
+				continue
+			}
+
+			state.InstanceTokens = append(state.InstanceTokens, key)
+			v, err := prune(state, subSchema, obj[key], nil)
+			state.InstanceTokens = state.InstanceTokens[:len(state.InstanceTokens)-1]
+
+			if err != nil {
+				return nil, err
+			}
+
+			out[key] = v
+		}
+
+		return out, nil
+	case FormValues:
+		obj, ok := instance.(map[string]interface{})
+		if !ok {
+			return instance, nil
+		}
+
+		out := make(map[string]interface{}, len(obj))
+		for _, key := range sortedInstanceKeys(obj) {
+			state.InstanceTokens = append(state.InstanceTokens, key)
+			v, err := prune(state, *schema.Values, obj[key], nil)
+			state.InstanceTokens = state.InstanceTokens[:len(state.InstanceTokens)-1]
+
+			if err != nil {
+				return nil, err
+			}
+
+			out[key] = v
+		}
+
+		return out, nil
+	case FormDiscriminator:
+		obj, ok := instance.(map[string]interface{})
+		if !ok {
+			return instance, nil
+		}
+
+		tag, ok := obj[schema.Discriminator].(string)
+		if !ok {
+			return instance, nil
+		}
+
+		mapping, ok := schema.Mapping[tag]
+		if !ok {
+			return instance, nil
+		}
+
+		return prune(state, mapping, instance, &schema.Discriminator)
+	default:
+		return instance, nil
+	}
+}
+
+func sortedInstanceKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
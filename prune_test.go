@@ -0,0 +1,107 @@
+package jtd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrune(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"user": {
+				"properties": { "name": { "type": "string" } },
+				"optionalProperties": { "extra": { "properties": {}, "additionalProperties": true } }
+			}
+		},
+		"properties": {
+			"users": { "elements": { "ref": "user" } },
+			"byId": { "values": { "ref": "user", "nullable": true } },
+			"event": {
+				"discriminator": "type",
+				"mapping": {
+					"a": { "properties": { "n": { "type": "uint32" } } }
+				}
+			},
+			"mismatched": { "properties": {} }
+		}
+	}`), &schema))
+
+	var instance interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"users": [
+			{ "name": "a", "age": 1, "extra": { "x": 1 } },
+			{ "name": "b" }
+		],
+		"byId": { "b": { "name": "b", "z": 1, "y": 2 }, "a": null },
+		"event": { "type": "a", "n": 1, "m": 2 },
+		"mismatched": [{ "x": 1 }],
+		"zzz": true
+	}`), &instance))
+
+	out, removed, err := jtd.Prune(schema, instance)
+	assert.NoError(t, err)
+
+	var expected interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"users": [
+			{ "name": "a", "extra": { "x": 1 } },
+			{ "name": "b" }
+		],
+		"byId": { "b": { "name": "b" }, "a": null },
+		"event": { "type": "a", "n": 1 },
+		"mismatched": [{ "x": 1 }]
+	}`), &expected))
+	assert.Equal(t, expected, out)
+
+	assert.Equal(t, [][]string{
+		[]string{"byId", "b", "y"},
+		[]string{"byId", "b", "z"},
+		[]string{"event", "m"},
+		[]string{"users", "0", "age"},
+		[]string{"zzz"},
+	}, removed)
+
+	errs, err := jtd.Validate(schema, out)
+	assert.NoError(t, err)
+	assert.Equal(t, []jtd.ValidateError{{
+		InstancePath: []string{"mismatched"},
+		SchemaPath:   []string{"properties", "mismatched", "properties"},
+	}}, errs)
+
+	// The original instance is left untouched.
+	assert.Contains(t, instance.(map[string]interface{}), "zzz")
+}
+
+func TestPruneUnproductiveRefCycle(t *testing.T) {
+	loop := "loop"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{"loop": jtd.Schema{Ref: &loop}},
+		Ref:         &loop,
+	}
+
+	_, _, err := jtd.Prune(schema, nil)
+	assert.Equal(t, jtd.ErrUnproductiveRefCycle, err)
+}
+
+func ExamplePrune() {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"name": jtd.Schema{Type: jtd.TypeString},
+		},
+	}
+
+	// As if sent by a newer client.
+	instance := map[string]interface{}{"name": "job", "priority": "high"}
+
+	out, removed, _ := jtd.Prune(schema, instance)
+	fmt.Println(out)
+	fmt.Println(removed)
+	// Output:
+	// map[name:job]
+	// [[priority]]
+}
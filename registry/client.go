@@ -0,0 +1,240 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+)
+
+// DefaultRefreshInterval is the RefreshInterval of a Client, unless set with
+// WithRefreshInterval.
+const DefaultRefreshInterval = time.Minute
+
+// ClientOption is an option to NewClient.
+type ClientOption func(*Client)
+
+// WithHTTPClient makes a Client send requests with c, instead of
+// http.DefaultClient.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(client *Client) {
+		client.httpClient = c
+	}
+}
+
+// WithRefreshInterval sets how long a Client relies on the versions it last
+// listed before listing them again.
+func WithRefreshInterval(d time.Duration) ClientOption {
+	return func(client *Client) {
+		client.refreshInterval = d
+	}
+}
+
+// Client is a client of a Server. It is safe for concurrent use.
+//
+// Because a registered schema never changes, Client fetches and compiles each
+// schema version at most once, and keeps it for as long as the Client is in
+// use. Which versions exist does change, so the list of versions that Latest
+// relies on is refreshed every RefreshInterval.
+type Client struct {
+	baseURL         string
+	httpClient      *http.Client
+	refreshInterval time.Duration
+
+	// mu guards the fields below it.
+	mu       sync.Mutex
+	compiled map[string]*jtd.CompiledSchema
+	versions map[string][]Version
+	listed   time.Time
+}
+
+// NewClient returns a Client of the Server at baseURL, such as
+// "http://localhost:8080".
+func NewClient(baseURL string, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:         strings.TrimSuffix(baseURL, "/"),
+		httpClient:      http.DefaultClient,
+		refreshInterval: DefaultRefreshInterval,
+		compiled:        map[string]*jtd.CompiledSchema{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// List returns the versions of every schema in the registry, keyed by name,
+// and sorted from lowest to highest.
+func (c *Client) List(ctx context.Context) (map[string][]Version, error) {
+	var out listResponse
+	if err := c.do(ctx, http.MethodGet, "/schemas", nil, &out); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.versions = out.Schemas
+	c.listed = time.Now()
+	c.mu.Unlock()
+
+	return out.Schemas, nil
+}
+
+// Latest returns the highest version of the schema with the given name. It
+// lists versions again only if it last did so more than RefreshInterval ago,
+// or if it doesn't know of the schema.
+func (c *Client) Latest(ctx context.Context, name string) (Version, error) {
+	c.mu.Lock()
+	versions, ok := c.versions[name]
+	fresh := time.Since(c.listed) < c.refreshInterval
+	c.mu.Unlock()
+
+	if !ok || !fresh {
+		all, err := c.List(ctx)
+		if err != nil {
+			return Version{}, err
+		}
+
+		versions = all[name]
+	}
+
+	if len(versions) == 0 {
+		return Version{}, fmt.Errorf("%w: %s", ErrNoSuchSchema, name)
+	}
+
+	return versions[len(versions)-1], nil
+}
+
+// Schema fetches the schema with the given name and version.
+func (c *Client) Schema(ctx context.Context, name string, version Version) (jtd.Schema, error) {
+	var schema jtd.Schema
+	if err := c.do(ctx, http.MethodGet, "/schemas/"+escapeID(name, version), nil, &schema); err != nil {
+		return jtd.Schema{}, err
+	}
+
+	return schema, nil
+}
+
+// Compiled returns the schema with the given name and version, compiled. It is
+// fetched only the first time it is asked for.
+func (c *Client) Compiled(ctx context.Context, name string, version Version) (*jtd.CompiledSchema, error) {
+	id := ID(name, version)
+
+	c.mu.Lock()
+	compiled, ok := c.compiled[id]
+	c.mu.Unlock()
+
+	if ok {
+		return compiled, nil
+	}
+
+	schema, err := c.Schema(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err = jtd.Compile(schema)
+	if err != nil {
+		return nil, fmt.Errorf("registry: %s: %w", id, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another goroutine may have fetched the schema meanwhile. Keep whichever
+	// was stored first, so that callers always get the same CompiledSchema.
+	if existing, ok := c.compiled[id]; ok {
+		return existing, nil
+	}
+
+	c.compiled[id] = compiled
+	return compiled, nil
+}
+
+// Validate validates instance against the schema with the given name and
+// version.
+func (c *Client) Validate(ctx context.Context, name string, version Version, instance interface{}, opts ...jtd.ValidateOption) ([]jtd.ValidateError, error) {
+	compiled, err := c.Compiled(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return compiled.Validate(instance, opts...)
+}
+
+// ValidateLatest validates instance against the highest version of the schema
+// with the given name, as returned by Latest, and returns that version.
+func (c *Client) ValidateLatest(ctx context.Context, name string, instance interface{}, opts ...jtd.ValidateOption) (Version, []jtd.ValidateError, error) {
+	version, err := c.Latest(ctx, name)
+	if err != nil {
+		return Version{}, nil, err
+	}
+
+	errs, err := c.Validate(ctx, name, version, instance, opts...)
+	return version, errs, err
+}
+
+// Register registers schema, a JSON-encoded schema, with the given name and
+// version. The server stores schema as-is.
+func (c *Client) Register(ctx context.Context, name string, version Version, schema json.RawMessage) error {
+	if err := c.do(ctx, http.MethodPut, "/schemas/"+escapeID(name, version), schema, nil); err != nil {
+		return err
+	}
+
+	// Make the next call to Latest see the new version.
+	c.mu.Lock()
+	c.listed = time.Time{}
+	c.mu.Unlock()
+
+	return nil
+}
+
+// CheckCompatible returns the error that Register would return, without
+// registering schema.
+func (c *Client) CheckCompatible(ctx context.Context, name string, version Version, schema json.RawMessage) error {
+	return c.do(ctx, http.MethodPost, "/compatibility/"+escapeID(name, version), schema, nil)
+}
+
+// do sends a request with the given body, and decodes the response into out,
+// unless out is nil. Error responses are returned as a *ServerError.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		serverErr := &ServerError{StatusCode: res.StatusCode}
+		if err := json.NewDecoder(res.Body).Decode(serverErr); err != nil || serverErr.Message == "" {
+			serverErr.Message = fmt.Sprintf("registry: %s %s: %s", method, path, res.Status)
+		}
+
+		return serverErr
+	}
+
+	if out == nil {
+		_, err := io.Copy(io.Discard, res.Body)
+		return err
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
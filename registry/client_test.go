@@ -0,0 +1,153 @@
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer starts a registry server in a temporary directory, and returns
+// its URL and a counter of the requests it receives.
+func newTestServer(t *testing.T) (string, *int64) {
+	server, err := registry.NewServer(t.TempDir())
+	assert.NoError(t, err)
+
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		server.ServeHTTP(w, req)
+	}))
+
+	t.Cleanup(ts.Close)
+	return ts.URL, &requests
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	v := registry.MustParseVersion
+
+	url, requests := newTestServer(t)
+	client := registry.NewClient(url+"/", registry.WithHTTPClient(&http.Client{Timeout: time.Minute}))
+
+	assert.NoError(t, client.Register(ctx, "user", v("1.0.0"), []byte(`{"properties": {"name": {"type": "string"}}}`)))
+	assert.NoError(t, client.CheckCompatible(ctx, "user", v("1.1.0"), []byte(`{"optionalProperties": {"name": {"type": "string"}}}`)))
+
+	err := client.CheckCompatible(ctx, "user", v("1.1.0"), []byte(`{"properties": {"name": {"type": "int8"}}}`))
+	assert.True(t, errors.Is(err, registry.ErrIncompatible))
+
+	var serverErr *registry.ServerError
+	assert.True(t, errors.As(err, &serverErr))
+	assert.Equal(t, http.StatusConflict, serverErr.StatusCode)
+
+	err = client.Register(ctx, "user", v("1.0.0"), []byte(`{}`))
+	assert.True(t, errors.Is(err, registry.ErrDuplicateVersion))
+
+	schema, err := client.Schema(ctx, "user", v("1.0.0"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, jtd.TypeString, schema.Properties["name"].Type)
+
+	_, err = client.Schema(ctx, "user", v("9.0.0"))
+	assert.True(t, errors.Is(err, registry.ErrNoSuchSchema))
+
+	versions, err := client.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]registry.Version{"user": {v("1.0.0")}}, versions)
+
+	// Schemas are fetched once, and then validated against locally.
+	atomic.StoreInt64(requests, 0)
+	for i := 0; i < 3; i++ {
+		errs, err := client.Validate(ctx, "user", v("1.0.0"), map[string]interface{}{"name": 1.0})
+		assert.NoError(t, err)
+		assert.Len(t, errs, 1)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt64(requests))
+
+	compiled, err := client.Compiled(ctx, "user", v("1.0.0"))
+	assert.NoError(t, err)
+	compiledAgain, err := client.Compiled(ctx, "user", v("1.0.0"))
+	assert.NoError(t, err)
+	assert.Same(t, compiled, compiledAgain)
+}
+
+func TestClientEscaping(t *testing.T) {
+	ctx := context.Background()
+	v := registry.MustParseVersion
+
+	url, _ := newTestServer(t)
+	client := registry.NewClient(url)
+
+	names := []string{"team a/user?x=1#y", "100%/b;c"}
+	for _, name := range names {
+		assert.NoError(t, client.Register(ctx, name, v("1.0.0"), []byte(`{"type": "string"}`)), name)
+		assert.NoError(t, client.CheckCompatible(ctx, name, v("1.1.0"), []byte(`{"type": "string"}`)), name)
+
+		schema, err := client.Schema(ctx, name, v("1.0.0"))
+		assert.NoError(t, err, name)
+		assert.EqualValues(t, jtd.TypeString, schema.Type, name)
+	}
+
+	versions, err := client.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]registry.Version{
+		"team a/user?x=1#y": {v("1.0.0")},
+		"100%/b;c":          {v("1.0.0")},
+	}, versions)
+}
+
+func TestClientLatest(t *testing.T) {
+	ctx := context.Background()
+	v := registry.MustParseVersion
+
+	url, _ := newTestServer(t)
+	publisher := registry.NewClient(url)
+	consumer := registry.NewClient(url, registry.WithRefreshInterval(time.Hour))
+
+	assert.NoError(t, publisher.Register(ctx, "event", v("1.0.0"), []byte(`{"enum": ["A"]}`)))
+
+	version, errs, err := consumer.ValidateLatest(ctx, "event", "B")
+	assert.NoError(t, err)
+	assert.Equal(t, v("1.0.0"), version)
+	assert.Len(t, errs, 1)
+
+	// The consumer doesn't see new versions until it refreshes.
+	assert.NoError(t, publisher.Register(ctx, "event", v("1.1.0"), []byte(`{"enum": ["A", "B"]}`)))
+
+	version, err = consumer.Latest(ctx, "event")
+	assert.NoError(t, err)
+	assert.Equal(t, v("1.0.0"), version)
+
+	_, err = consumer.List(ctx)
+	assert.NoError(t, err)
+
+	version, errs, err = consumer.ValidateLatest(ctx, "event", "B")
+	assert.NoError(t, err)
+	assert.Equal(t, v("1.1.0"), version)
+	assert.Empty(t, errs)
+
+	// Unknown schemas are looked up again, in case they were just registered.
+	_, err = consumer.Latest(ctx, "other")
+	assert.True(t, errors.Is(err, registry.ErrNoSuchSchema))
+
+	assert.NoError(t, publisher.Register(ctx, "other", v("0.1.0"), []byte(`{}`)))
+	version, err = consumer.Latest(ctx, "other")
+	assert.NoError(t, err)
+	assert.Equal(t, v("0.1.0"), version)
+
+	// With a zero refresh interval, the list is refreshed every time.
+	eager := registry.NewClient(url, registry.WithRefreshInterval(0))
+	_, err = eager.Latest(ctx, "event")
+	assert.NoError(t, err)
+
+	assert.NoError(t, publisher.Register(ctx, "event", v("1.2.0"), []byte(`{"type": "string"}`)))
+	version, err = eager.Latest(ctx, "event")
+	assert.NoError(t, err)
+	assert.Equal(t, v("1.2.0"), version)
+}
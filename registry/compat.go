@@ -0,0 +1,266 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// ErrIncompatible indicates that a schema does not accept every instance that
+// an earlier version of it accepts.
+var ErrIncompatible = errors.New("registry: incompatible schema versions")
+
+// CheckCompatible returns an error wrapping ErrIncompatible if newer, a root
+// schema, might not accept an instance that older, also a root schema,
+// accepts. In other words, data that was valid against older stays valid
+// against newer.
+//
+// The check is conservative: it compares schemas keyword by keyword, and so it
+// may reject changes that are in fact compatible. Compatible changes include
+// adding optional properties, allowing null, adding enum values, widening
+// integer types, replacing "timestamp" or an enum with "string", and replacing
+// anything with the empty form. Every definition of older must also exist in
+// newer, and be compatible with it, so that instances can keep being validated
+// against definitions by name.
+//
+// The error message points to the part of older that newer is not compatible
+// with.
+func CheckCompatible(older, newer jtd.Schema) error {
+	c := compatChecker{older: older, newer: newer, seen: map[string]bool{}}
+	if err := c.check([]string{}, older, []string{}, newer); err != nil {
+		return err
+	}
+
+	for _, name := range schemautil.SortedKeys(older.Definitions) {
+		newDef, ok := newer.Definitions[name]
+		if !ok {
+			return c.errorf([]string{"definitions", name}, "definition was removed")
+		}
+
+		path := []string{"definitions", name}
+		if err := c.check(path, older.Definitions[name], path, newDef); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type compatChecker struct {
+	older jtd.Schema
+	newer jtd.Schema
+
+	// seen holds the pairs of subschemas, identified by their paths, that are
+	// being checked or were found to be compatible. Assuming that they are
+	// compatible when they come up again is what makes checking recursive
+	// schemas terminate.
+	seen map[string]bool
+}
+
+func (c *compatChecker) errorf(path []string, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: at %q: %s", ErrIncompatible, "/"+strings.Join(path, "/"), fmt.Sprintf(format, args...))
+}
+
+func (c *compatChecker) check(oldPath []string, old jtd.Schema, newPath []string, new jtd.Schema) error {
+	key := strings.Join(oldPath, "\x00") + "\x01" + strings.Join(newPath, "\x00")
+	if c.seen[key] {
+		return nil
+	}
+
+	c.seen[key] = true
+
+	if old.Nullable && !c.acceptsNull(new, map[string]bool{}) {
+		return c.errorf(oldPath, "null is no longer accepted")
+	}
+
+	// From here on, only non-null instances matter, which refs don't change.
+	if old.Ref != nil {
+		return c.check([]string{"definitions", *old.Ref}, c.older.Definitions[*old.Ref], newPath, new)
+	}
+
+	if new.Ref != nil {
+		return c.check(oldPath, old, []string{"definitions", *new.Ref}, c.newer.Definitions[*new.Ref])
+	}
+
+	oldForm := old.Form()
+	newForm := new.Form()
+
+	if newForm == jtd.FormEmpty {
+		return nil
+	}
+
+	switch oldForm {
+	case jtd.FormEmpty:
+		return c.errorf(oldPath, "any value was accepted, but is now restricted to the %s form", newForm)
+	case jtd.FormType:
+		if newForm == jtd.FormType && typeAccepts(new.Type, old.Type) {
+			return nil
+		}
+
+		if newForm == jtd.FormType {
+			return c.errorf(oldPath, "type %q was changed to %q", old.Type, new.Type)
+		}
+	case jtd.FormEnum:
+		if newForm == jtd.FormType && new.Type == jtd.TypeString {
+			return nil
+		}
+
+		if newForm == jtd.FormEnum {
+			values := map[string]bool{}
+			for _, v := range new.Enum {
+				values[v] = true
+			}
+
+			for _, v := range old.Enum {
+				if !values[v] {
+					return c.errorf(oldPath, "enum value %q was removed", v)
+				}
+			}
+
+			return nil
+		}
+	case jtd.FormElements:
+		if newForm == jtd.FormElements {
+			return c.check(schemautil.AppendPath(oldPath, "elements"), *old.Elements, schemautil.AppendPath(newPath, "elements"), *new.Elements)
+		}
+	case jtd.FormValues:
+		if newForm == jtd.FormValues {
+			return c.check(schemautil.AppendPath(oldPath, "values"), *old.Values, schemautil.AppendPath(newPath, "values"), *new.Values)
+		}
+	case jtd.FormProperties:
+		if newForm == jtd.FormProperties {
+			return c.checkProperties(oldPath, old, newPath, new)
+		}
+	case jtd.FormDiscriminator:
+		if newForm == jtd.FormDiscriminator {
+			if old.Discriminator != new.Discriminator {
+				return c.errorf(oldPath, "discriminator %q was changed to %q", old.Discriminator, new.Discriminator)
+			}
+
+			for _, tag := range schemautil.SortedKeys(old.Mapping) {
+				newMapping, ok := new.Mapping[tag]
+				if !ok {
+					return c.errorf(oldPath, "mapping %q was removed", tag)
+				}
+
+				if err := c.check(schemautil.AppendPath(oldPath, "mapping", tag), old.Mapping[tag], schemautil.AppendPath(newPath, "mapping", tag), newMapping); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	return c.errorf(oldPath, "%s form was changed to the %s form", oldForm, newForm)
+}
+
+func (c *compatChecker) checkProperties(oldPath []string, old jtd.Schema, newPath []string, new jtd.Schema) error {
+	for _, name := range schemautil.SortedKeys(new.Properties) {
+		if _, ok := old.Properties[name]; !ok {
+			return c.errorf(oldPath, "property %q became required", name)
+		}
+	}
+
+	for _, keyword := range []string{"properties", "optionalProperties"} {
+		properties := old.Properties
+		if keyword == "optionalProperties" {
+			properties = old.OptionalProperties
+		}
+
+		for _, name := range schemautil.SortedKeys(properties) {
+			subPath := schemautil.AppendPath(oldPath, keyword, name)
+
+			if newSchema, ok := new.Properties[name]; ok {
+				if err := c.check(subPath, properties[name], schemautil.AppendPath(newPath, "properties", name), newSchema); err != nil {
+					return err
+				}
+			} else if newSchema, ok := new.OptionalProperties[name]; ok {
+				if err := c.check(subPath, properties[name], schemautil.AppendPath(newPath, "optionalProperties", name), newSchema); err != nil {
+					return err
+				}
+			} else if !new.AdditionalProperties {
+				return c.errorf(subPath, "property %q was removed", name)
+			}
+		}
+	}
+
+	if old.AdditionalProperties {
+		if !new.AdditionalProperties {
+			return c.errorf(oldPath, "additional properties are no longer allowed")
+		}
+
+		// Instances could have had any value for properties newer adds.
+		for _, name := range schemautil.SortedKeys(new.OptionalProperties) {
+			if _, ok := old.OptionalProperties[name]; ok {
+				continue
+			}
+
+			if _, ok := old.Properties[name]; ok {
+				continue
+			}
+
+			subPath := schemautil.AppendPath(oldPath, "additionalProperties", name)
+			if err := c.check(subPath, jtd.Schema{}, schemautil.AppendPath(newPath, "optionalProperties", name), new.OptionalProperties[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// acceptsNull returns whether s, a schema in newer, accepts null.
+func (c *compatChecker) acceptsNull(s jtd.Schema, seen map[string]bool) bool {
+	if s.Nullable {
+		return true
+	}
+
+	if s.Ref != nil {
+		// An unproductive ref cycle never accepts anything.
+		if seen[*s.Ref] {
+			return false
+		}
+
+		seen[*s.Ref] = true
+		return c.acceptsNull(c.newer.Definitions[*s.Ref], seen)
+	}
+
+	return s.Form() == jtd.FormEmpty
+}
+
+// numberRanges are the ranges of numbers that the numeric types accept.
+var numberRanges = map[jtd.Type][2]float64{
+	jtd.TypeInt8:   {-128, 127},
+	jtd.TypeUint8:  {0, 255},
+	jtd.TypeInt16:  {-32768, 32767},
+	jtd.TypeUint16: {0, 65535},
+	jtd.TypeInt32:  {-2147483648, 2147483647},
+	jtd.TypeUint32: {0, 4294967295},
+}
+
+// typeAccepts returns whether every value of type old is also of type new.
+func typeAccepts(new, old jtd.Type) bool {
+	if new == old {
+		return true
+	}
+
+	switch new {
+	case jtd.TypeString:
+		return old == jtd.TypeTimestamp
+	case jtd.TypeFloat32, jtd.TypeFloat64:
+		_, isInt := numberRanges[old]
+		return isInt || old == jtd.TypeFloat32 || old == jtd.TypeFloat64
+	}
+
+	newRange, ok := numberRanges[new]
+	if !ok {
+		return false
+	}
+
+	oldRange, ok := numberRanges[old]
+	return ok && newRange[0] <= oldRange[0] && oldRange[1] <= newRange[1]
+}
@@ -0,0 +1,193 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCompatible(t *testing.T) {
+	testCases := []struct {
+		name  string
+		old   string
+		new   string
+		error string
+	}{
+		{"same schema", `{"type": "string"}`, `{"type": "string"}`, ""},
+		{"anything to empty", `{"elements": {"type": "string"}}`, `{}`, ""},
+		{"empty to type", `{}`, `{"type": "string"}`, `at "/": any value was accepted`},
+		{"allow null", `{"type": "string"}`, `{"type": "string", "nullable": true}`, ""},
+		{"disallow null", `{"type": "string", "nullable": true}`, `{"type": "string"}`, `at "/": null is no longer accepted`},
+		{"widen int", `{"type": "int8"}`, `{"type": "int32"}`, ""},
+		{"uint to wider int", `{"type": "uint16"}`, `{"type": "int32"}`, ""},
+		{"uint to same size int", `{"type": "uint16"}`, `{"type": "int16"}`, `type "uint16" was changed to "int16"`},
+		{"narrow int", `{"type": "int32"}`, `{"type": "int16"}`, `type "int32" was changed to "int16"`},
+		{"int to float", `{"type": "uint32"}`, `{"type": "float32"}`, ""},
+		{"float to int", `{"type": "float64"}`, `{"type": "int32"}`, `type "float64" was changed`},
+		{"float32 to float64", `{"type": "float32"}`, `{"type": "float64"}`, ""},
+		{"timestamp to string", `{"type": "timestamp"}`, `{"type": "string"}`, ""},
+		{"string to timestamp", `{"type": "string"}`, `{"type": "timestamp"}`, `type "string" was changed`},
+		{"add enum value", `{"enum": ["A"]}`, `{"enum": ["A", "B"]}`, ""},
+		{"remove enum value", `{"enum": ["A", "B"]}`, `{"enum": ["A"]}`, `enum value "B" was removed`},
+		{"enum to string", `{"enum": ["A"]}`, `{"type": "string"}`, ""},
+		{"change form", `{"type": "string"}`, `{"enum": ["A"]}`, `type form was changed to the enum form`},
+		{"elements", `{"elements": {"type": "int8"}}`, `{"elements": {"type": "int8"}}`, ""},
+		{"bad elements", `{"elements": {"type": "int8"}}`, `{"elements": {"type": "uint8"}}`, `at "/elements"`},
+		{"bad values", `{"values": {"type": "int8"}}`, `{"values": {"type": "uint8"}}`, `at "/values"`},
+		{
+			"add optional property",
+			`{"properties": {"a": {}}}`,
+			`{"properties": {"a": {}}, "optionalProperties": {"b": {}}}`,
+			"",
+		},
+		{
+			"add required property",
+			`{"properties": {"a": {}}}`,
+			`{"properties": {"a": {}, "b": {}}}`,
+			`at "/": property "b" became required`,
+		},
+		{
+			"make property optional",
+			`{"properties": {"a": {}}}`,
+			`{"optionalProperties": {"a": {}}}`,
+			"",
+		},
+		{
+			"make property required",
+			`{"optionalProperties": {"a": {}}}`,
+			`{"properties": {"a": {}}}`,
+			`property "a" became required`,
+		},
+		{
+			"remove property",
+			`{"properties": {"a": {}}}`,
+			`{"properties": {}}`,
+			`at "/properties/a": property "a" was removed`,
+		},
+		{
+			"remove property with additional properties",
+			`{"properties": {"a": {}}}`,
+			`{"properties": {}, "additionalProperties": true}`,
+			"",
+		},
+		{
+			"disallow additional properties",
+			`{"properties": {}, "additionalProperties": true}`,
+			`{"properties": {}}`,
+			`additional properties are no longer allowed`,
+		},
+		{
+			"restrict additional property",
+			`{"properties": {}, "additionalProperties": true}`,
+			`{"properties": {}, "optionalProperties": {"a": {"type": "string"}}, "additionalProperties": true}`,
+			`at "/additionalProperties/a": any value was accepted`,
+		},
+		{
+			"bad property",
+			`{"optionalProperties": {"a": {"type": "string"}}}`,
+			`{"optionalProperties": {"a": {"type": "boolean"}}}`,
+			`at "/optionalProperties/a"`,
+		},
+		{
+			"add mapping",
+			`{"discriminator": "t", "mapping": {"a": {"properties": {}}}}`,
+			`{"discriminator": "t", "mapping": {"a": {"properties": {}}, "b": {"properties": {}}}}`,
+			"",
+		},
+		{
+			"remove mapping",
+			`{"discriminator": "t", "mapping": {"a": {"properties": {}}}}`,
+			`{"discriminator": "t", "mapping": {}}`,
+			`mapping "a" was removed`,
+		},
+		{
+			"change discriminator",
+			`{"discriminator": "t", "mapping": {}}`,
+			`{"discriminator": "u", "mapping": {}}`,
+			`discriminator "t" was changed to "u"`,
+		},
+		{
+			"bad mapping",
+			`{"discriminator": "t", "mapping": {"a": {"properties": {"x": {}}}}}`,
+			`{"discriminator": "t", "mapping": {"a": {"properties": {"y": {}}}}}`,
+			`property "y" became required`,
+		},
+		{
+			"inline ref",
+			`{"definitions": {"a": {"type": "int8"}}, "ref": "a"}`,
+			`{"definitions": {"a": {"type": "int8"}}, "type": "int16"}`,
+			"",
+		},
+		{
+			"bad ref",
+			`{"definitions": {"a": {"type": "int8"}}, "elements": {"ref": "a"}}`,
+			`{"definitions": {"a": {"type": "uint8"}}, "elements": {"ref": "a"}}`,
+			`at "/definitions/a"`,
+		},
+		{
+			"nullable ref",
+			`{"definitions": {"a": {"type": "int8"}}, "ref": "a", "nullable": true}`,
+			`{"definitions": {"a": {"type": "int8"}, "b": {"type": "int8", "nullable": true}}, "ref": "b"}`,
+			"",
+		},
+		{
+			"remove definition",
+			`{"definitions": {"a": {}}}`,
+			`{}`,
+			`at "/definitions/a": definition was removed`,
+		},
+		{
+			"recursive",
+			`{"definitions": {"a": {"elements": {"ref": "a"}}}, "ref": "a"}`,
+			`{"definitions": {"a": {"elements": {"ref": "a"}}, "b": {"elements": {"elements": {"ref": "b"}}}}, "ref": "a"}`,
+			"",
+		},
+		{
+			"recursive with different shape",
+			`{"definitions": {"a": {"elements": {"ref": "a", "nullable": true}}}, "ref": "a"}`,
+			`{"definitions": {"a": {"elements": {"elements": {"ref": "a"}, "nullable": true}}}, "ref": "a"}`,
+			`at "/definitions/a/elements": null is no longer accepted`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var old, new jtd.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.old), &old))
+			assert.NoError(t, json.Unmarshal([]byte(tt.new), &new))
+			assert.NoError(t, old.Validate())
+			assert.NoError(t, new.Validate())
+
+			err := registry.CheckCompatible(old, new)
+			if tt.error == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, registry.ErrIncompatible))
+				assert.Contains(t, err.Error(), tt.error)
+			}
+		})
+	}
+}
+
+func ExampleCheckCompatible() {
+	older := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"count": {Type: jtd.TypeUint8},
+		},
+	}
+
+	newer := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"count": {Type: jtd.TypeInt8},
+		},
+	}
+
+	fmt.Println(registry.CheckCompatible(older, newer))
+	// Output:
+	// registry: incompatible schema versions: at "/properties/count": type "uint8" was changed to "int8"
+}
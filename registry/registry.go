@@ -0,0 +1,392 @@
+// Package registry holds JSON Typedef schemas by name and semantic version.
+//
+// A Registry makes sure that every schema it holds is valid, and that versions
+// of a schema with the same major version are compatible with one another:
+// each version accepts every instance that the versions before it accept.
+// Breaking changes require a new major version.
+//
+// Registries are typically loaded from a directory tree, or an fs.FS such as
+// an embed.FS, where each schema is in a file named after its version:
+//
+//	schemas/
+//	  billing/invoice/1.0.0.jtd.json
+//	  billing/invoice/1.1.0.jtd.json
+//	  billing/invoice/2.0.0.jtd.json
+//	  user/1.0.0.jtd.json
+//
+// Schemas are identified by their name and version, which can be written
+// together as an ID such as "billing/invoice@1.1.0".
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+)
+
+// FileExt is the extension of schema files read by Load.
+const FileExt = ".jtd.json"
+
+// ErrNoSuchSchema indicates that a registry does not have a schema with the
+// given name or version.
+var ErrNoSuchSchema = errors.New("registry: no such schema")
+
+// ErrDuplicateVersion indicates that a registry already has a schema with the
+// given name and version.
+var ErrDuplicateVersion = errors.New("registry: duplicate schema version")
+
+// ErrInvalidName indicates that a string is not a valid schema name.
+var ErrInvalidName = errors.New("registry: invalid schema name")
+
+// ErrInvalidID indicates that a string is not a valid schema ID.
+var ErrInvalidID = errors.New("registry: invalid schema ID")
+
+// ID returns the ID of the schema with the given name and version, of the form
+// "name@version".
+func ID(name string, version Version) string {
+	return name + "@" + version.String()
+}
+
+// ParseID parses an ID of the form "name@version", such as "user@1.0.0".
+func ParseID(id string) (string, Version, error) {
+	i := strings.LastIndexByte(id, '@')
+	if i == -1 {
+		return "", Version{}, fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+
+	name := id[:i]
+	if err := checkName(name); err != nil {
+		return "", Version{}, fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+
+	version, err := ParseVersion(id[i+1:])
+	if err != nil {
+		return "", Version{}, fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+
+	return name, version, nil
+}
+
+// escapeID returns ID(name, version), with each of its slash-separated segments
+// escaped so that it can be used in a URL path.
+func escapeID(name string, version Version) string {
+	segments := strings.Split(ID(name, version), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// parseEscapedID parses an ID escaped by escapeID.
+func parseEscapedID(escaped string) (string, Version, error) {
+	segments := strings.Split(escaped, "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", Version{}, fmt.Errorf("%w: %q", ErrInvalidID, escaped)
+		}
+
+		segments[i] = unescaped
+	}
+
+	return ParseID(strings.Join(segments, "/"))
+}
+
+// checkName returns an error if name is not a valid schema name. Names are
+// slash-separated paths, like those of fs.FS, that don't contain "@".
+func checkName(name string) error {
+	if name == "." || !fs.ValidPath(name) || strings.ContainsRune(name, '@') {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	return nil
+}
+
+// Registry is a set of schemas, keyed by name and version. It is safe for
+// concurrent use.
+type Registry struct {
+	mu sync.RWMutex
+
+	// schemas holds the versions of each schema, sorted by version.
+	schemas map[string][]*entry
+}
+
+type entry struct {
+	version Version
+	schema  jtd.Schema
+
+	// compiled is computed the first time the schema is used for validation.
+	once     sync.Once
+	compiled *jtd.CompiledSchema
+	err      error
+}
+
+func (e *entry) compile() (*jtd.CompiledSchema, error) {
+	e.once.Do(func() {
+		e.compiled, e.err = jtd.Compile(e.schema)
+	})
+
+	return e.compiled, e.err
+}
+
+// New returns an empty Registry.
+func New() *Registry {
+	return &Registry{schemas: map[string][]*entry{}}
+}
+
+// Load returns a Registry with every schema in fsys. Each file ending in
+// FileExt is a schema, whose name is the directory it is in, and whose version
+// is its file name without the extension. Other files are ignored.
+//
+// Versions of each schema are added in order, so Load returns an error if
+// Add would return an error for any of them.
+func Load(fsys fs.FS) (*Registry, error) {
+	type file struct {
+		path    string
+		name    string
+		version Version
+	}
+
+	var files []file
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(p, FileExt) {
+			return nil
+		}
+
+		name, base := path.Split(p)
+		name = strings.TrimSuffix(name, "/")
+		if name == "" {
+			return fmt.Errorf("%w: %s is not in a directory", ErrInvalidName, p)
+		}
+
+		version, err := ParseVersion(strings.TrimSuffix(base, FileExt))
+		if err != nil {
+			return fmt.Errorf("registry: %s: %w", p, err)
+		}
+
+		files = append(files, file{path: p, name: name, version: version})
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].name != files[j].name {
+			return files[i].name < files[j].name
+		}
+
+		return files[i].version.Compare(files[j].version) < 0
+	})
+
+	r := New()
+	for _, f := range files {
+		data, err := fs.ReadFile(fsys, f.path)
+		if err != nil {
+			return nil, err
+		}
+
+		var schema jtd.Schema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, fmt.Errorf("registry: %s: %w", f.path, err)
+		}
+
+		if err := r.Add(f.name, f.version, schema); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// LoadDir is like Load, but reads the schemas in the directory tree rooted at
+// dir.
+func LoadDir(dir string) (*Registry, error) {
+	return Load(os.DirFS(dir))
+}
+
+// Add adds schema to r with the given name and version.
+//
+// Add returns an error if schema is not valid according to Schema.Validate, if
+// r already has the given version, or if schema is not compatible with the
+// versions next to it with the same major version, according to
+// CheckCompatible.
+func (r *Registry) Add(name string, version Version, schema jtd.Schema) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.insertIndex(name, version, schema)
+	if err != nil {
+		return err
+	}
+
+	entries := append(r.schemas[name], nil)
+	copy(entries[i+1:], entries[i:])
+	entries[i] = &entry{version: version, schema: schema}
+	r.schemas[name] = entries
+
+	return nil
+}
+
+// CheckAdd returns the error that Add would return, without adding schema to
+// r.
+func (r *Registry) CheckAdd(name string, version Version, schema jtd.Schema) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, err := r.insertIndex(name, version, schema)
+	return err
+}
+
+// insertIndex returns the index in r.schemas[name] that schema can be added
+// at, or the error that Add returns. r.mu must be held.
+func (r *Registry) insertIndex(name string, version Version, schema jtd.Schema) (int, error) {
+	if err := checkName(name); err != nil {
+		return 0, err
+	}
+
+	id := ID(name, version)
+	if err := schema.Validate(); err != nil {
+		return 0, fmt.Errorf("registry: %s: %w", id, err)
+	}
+
+	entries := r.schemas[name]
+	i := sort.Search(len(entries), func(i int) bool {
+		return entries[i].version.Compare(version) >= 0
+	})
+
+	if i < len(entries) && entries[i].version == version {
+		return 0, fmt.Errorf("%w: %s", ErrDuplicateVersion, id)
+	}
+
+	if i > 0 && entries[i-1].version.Major == version.Major {
+		if err := CheckCompatible(entries[i-1].schema, schema); err != nil {
+			return 0, fmt.Errorf("registry: %s: not compatible with %s: %w", id, entries[i-1].version, err)
+		}
+	}
+
+	if i < len(entries) && entries[i].version.Major == version.Major {
+		if err := CheckCompatible(schema, entries[i].schema); err != nil {
+			return 0, fmt.Errorf("registry: %s: %s is not compatible with it: %w", id, entries[i].version, err)
+		}
+	}
+
+	return i, nil
+}
+
+// Names returns the names of the schemas in r, in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.schemas))
+	for name := range r.schemas {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Versions returns the versions of the schema with the given name, from lowest
+// to highest. It returns nil if r has no such schema.
+func (r *Registry) Versions(name string) []Version {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var versions []Version
+	for _, e := range r.schemas[name] {
+		versions = append(versions, e.version)
+	}
+
+	return versions
+}
+
+// Schema returns the schema with the given name and version.
+func (r *Registry) Schema(name string, version Version) (jtd.Schema, error) {
+	e, err := r.entry(name, version)
+	if err != nil {
+		return jtd.Schema{}, err
+	}
+
+	return e.schema, nil
+}
+
+// Lookup returns the schema with the given ID, of the form "name@version".
+func (r *Registry) Lookup(id string) (jtd.Schema, error) {
+	name, version, err := ParseID(id)
+	if err != nil {
+		return jtd.Schema{}, err
+	}
+
+	return r.Schema(name, version)
+}
+
+// Latest returns the highest version of the schema with the given name, and
+// the schema itself.
+func (r *Registry) Latest(name string) (Version, jtd.Schema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := r.schemas[name]
+	if len(entries) == 0 {
+		return Version{}, jtd.Schema{}, fmt.Errorf("%w: %s", ErrNoSuchSchema, name)
+	}
+
+	e := entries[len(entries)-1]
+	return e.version, e.schema, nil
+}
+
+// Compiled returns the schema with the given name and version, compiled. The
+// schema is compiled only once, and later calls return the same
+// CompiledSchema.
+func (r *Registry) Compiled(name string, version Version) (*jtd.CompiledSchema, error) {
+	e, err := r.entry(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.compile()
+}
+
+// Validate validates instance against the schema with the given name and
+// version. It is equivalent to calling Validate on the result of Compiled.
+func (r *Registry) Validate(name string, version Version, instance interface{}, opts ...jtd.ValidateOption) ([]jtd.ValidateError, error) {
+	compiled, err := r.Compiled(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return compiled.Validate(instance, opts...)
+}
+
+func (r *Registry) entry(name string, version Version) (*entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := r.schemas[name]
+	i := sort.Search(len(entries), func(i int) bool {
+		return entries[i].version.Compare(version) >= 0
+	})
+
+	if i == len(entries) || entries[i].version != version {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchSchema, ID(name, version))
+	}
+
+	return entries[i], nil
+}
@@ -0,0 +1,202 @@
+package registry_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDir(t *testing.T) {
+	r, err := registry.LoadDir("testdata/schemas")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"billing/invoice", "user"}, r.Names())
+	assert.Equal(t, []registry.Version{
+		registry.MustParseVersion("1.0.0"),
+		registry.MustParseVersion("1.1.0"),
+	}, r.Versions("user"))
+	assert.Nil(t, r.Versions("nonexistent"))
+
+	version, schema, err := r.Latest("billing/invoice")
+	assert.NoError(t, err)
+	assert.Equal(t, registry.MustParseVersion("2.0.0"), version)
+	assert.EqualValues(t, jtd.TypeString, schema.Properties["amount"].Type)
+
+	schema, err = r.Lookup("billing/invoice@1.0.0")
+	assert.NoError(t, err)
+	assert.EqualValues(t, jtd.TypeInt32, schema.Properties["amount"].Type)
+
+	errs, err := r.Validate("user", registry.MustParseVersion("1.1.0"), map[string]interface{}{
+		"name": "John",
+		"age":  -1.0,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []jtd.ValidateError{{
+		InstancePath: []string{"age"},
+		SchemaPath:   []string{"optionalProperties", "age", "type"},
+	}}, errs)
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/1.0.0.jtd.json":  {Data: []byte(`{"enum": ["X"]}`)},
+		"a/1.2.0.jtd.json":  {Data: []byte(`{"enum": ["X", "Y", "Z"]}`)},
+		"a/1.10.0.jtd.json": {Data: []byte(`{"type": "string"}`)},
+		"a/notes.txt":       {Data: []byte("not a schema")},
+	}
+
+	r, err := registry.Load(fsys)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, r.Names())
+
+	version, _, err := r.Latest("a")
+	assert.NoError(t, err)
+	assert.Equal(t, registry.MustParseVersion("1.10.0"), version)
+
+	testCases := []struct {
+		name string
+		fsys fstest.MapFS
+		err  error
+	}{
+		{
+			name: "invalid JSON",
+			fsys: fstest.MapFS{"a/1.0.0.jtd.json": {Data: []byte(`{`)}},
+		},
+		{
+			name: "invalid schema",
+			fsys: fstest.MapFS{"a/1.0.0.jtd.json": {Data: []byte(`{"type": "foo"}`)}},
+			err:  jtd.ErrInvalidType,
+		},
+		{
+			name: "invalid version",
+			fsys: fstest.MapFS{"a/1.0.jtd.json": {Data: []byte(`{}`)}},
+			err:  registry.ErrInvalidVersion,
+		},
+		{
+			name: "not in a directory",
+			fsys: fstest.MapFS{"1.0.0.jtd.json": {Data: []byte(`{}`)}},
+			err:  registry.ErrInvalidName,
+		},
+		{
+			name: "incompatible versions",
+			fsys: fstest.MapFS{
+				"a/1.0.0.jtd.json": {Data: []byte(`{"enum": ["X", "Y"]}`)},
+				"a/1.1.0.jtd.json": {Data: []byte(`{"enum": ["X"]}`)},
+			},
+			err: registry.ErrIncompatible,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := registry.Load(tt.fsys)
+			assert.Error(t, err)
+
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err), err)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	v := registry.MustParseVersion
+	r := registry.New()
+
+	assert.NoError(t, r.Add("a", v("1.0.0"), jtd.Schema{Type: jtd.TypeInt8}))
+	assert.NoError(t, r.Add("a", v("1.2.0"), jtd.Schema{Type: jtd.TypeInt32}))
+
+	// Versions may be added out of order, but must be compatible with the
+	// versions on both sides of them.
+	assert.NoError(t, r.Add("a", v("1.1.0"), jtd.Schema{Type: jtd.TypeInt16}))
+	assert.True(t, errors.Is(r.Add("a", v("1.1.1"), jtd.Schema{Type: jtd.TypeInt8}), registry.ErrIncompatible))
+	assert.True(t, errors.Is(r.Add("a", v("1.1.1"), jtd.Schema{Type: jtd.TypeFloat64}), registry.ErrIncompatible))
+
+	// A new major version may break compatibility.
+	assert.NoError(t, r.Add("a", v("2.0.0"), jtd.Schema{Type: jtd.TypeString}))
+
+	assert.True(t, errors.Is(r.Add("a", v("1.0.0"), jtd.Schema{Type: jtd.TypeInt8}), registry.ErrDuplicateVersion))
+	assert.True(t, errors.Is(r.Add("a", v("3.0.0"), jtd.Schema{Type: "foo"}), jtd.ErrInvalidType))
+
+	for _, name := range []string{"", ".", "/a", "a/", "a/../b", "a@b"} {
+		assert.True(t, errors.Is(r.Add(name, v("1.0.0"), jtd.Schema{}), registry.ErrInvalidName), name)
+	}
+
+	assert.Equal(t, []registry.Version{v("1.0.0"), v("1.1.0"), v("1.2.0"), v("2.0.0")}, r.Versions("a"))
+}
+
+func TestNoSuchSchema(t *testing.T) {
+	r := registry.New()
+	assert.NoError(t, r.Add("a", registry.MustParseVersion("1.0.0"), jtd.Schema{}))
+
+	_, err := r.Schema("a", registry.MustParseVersion("1.0.1"))
+	assert.True(t, errors.Is(err, registry.ErrNoSuchSchema))
+
+	_, err = r.Lookup("b@1.0.0")
+	assert.True(t, errors.Is(err, registry.ErrNoSuchSchema))
+
+	_, _, err = r.Latest("b")
+	assert.True(t, errors.Is(err, registry.ErrNoSuchSchema))
+
+	_, err = r.Validate("b", registry.MustParseVersion("1.0.0"), nil)
+	assert.True(t, errors.Is(err, registry.ErrNoSuchSchema))
+}
+
+func TestParseID(t *testing.T) {
+	name, version, err := registry.ParseID("billing/invoice@1.2.3")
+	assert.NoError(t, err)
+	assert.Equal(t, "billing/invoice", name)
+	assert.Equal(t, registry.MustParseVersion("1.2.3"), version)
+	assert.Equal(t, "billing/invoice@1.2.3", registry.ID(name, version))
+
+	for _, id := range []string{"", "a", "a@", "@1.0.0", "a@1.0", "a@b@1.0.0", "/a@1.0.0"} {
+		_, _, err := registry.ParseID(id)
+		assert.True(t, errors.Is(err, registry.ErrInvalidID), id)
+	}
+}
+
+func TestCompiled(t *testing.T) {
+	r := registry.New()
+	assert.NoError(t, r.Add("a", registry.MustParseVersion("1.0.0"), jtd.Schema{Type: jtd.TypeString}))
+
+	var wg sync.WaitGroup
+	compiled := make([]*jtd.CompiledSchema, 8)
+	for i := range compiled {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			c, err := r.Compiled("a", registry.MustParseVersion("1.0.0"))
+			assert.NoError(t, err)
+			compiled[i] = c
+		}(i)
+	}
+
+	wg.Wait()
+
+	// Every caller gets the same compiled schema.
+	for _, c := range compiled {
+		assert.Same(t, compiled[0], c)
+	}
+}
+
+func ExampleRegistry_Validate() {
+	r, err := registry.LoadDir("testdata/schemas")
+	if err != nil {
+		panic(err)
+	}
+
+	errs, err := r.Validate("user", registry.MustParseVersion("1.0.0"), map[string]interface{}{
+		"name": "John",
+	})
+
+	fmt.Println(errs, err)
+	// Output:
+	// [] <nil>
+}
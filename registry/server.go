@@ -0,0 +1,301 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+)
+
+// maxSchemaSize is the largest request body, in bytes, that Server accepts.
+const maxSchemaSize = 1 << 20
+
+// errorCodes are the codes that Server reports errors with, and the errors
+// they correspond to. Codes are checked in order.
+var errorCodes = []struct {
+	code   string
+	err    error
+	status int
+}{
+	{"no_such_schema", ErrNoSuchSchema, http.StatusNotFound},
+	{"duplicate_version", ErrDuplicateVersion, http.StatusConflict},
+	{"incompatible", ErrIncompatible, http.StatusConflict},
+	{"invalid_id", ErrInvalidID, http.StatusBadRequest},
+	{"invalid_name", ErrInvalidName, http.StatusBadRequest},
+}
+
+// ServerError is an error reported by a Server. It wraps the error it
+// corresponds to, such as ErrNoSuchSchema or ErrIncompatible, if any.
+type ServerError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"-"`
+
+	// Code identifies the kind of error, such as "no_such_schema".
+	Code string `json:"code"`
+
+	// Message describes the error.
+	Message string `json:"message"`
+}
+
+func (e *ServerError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the error that e.Code corresponds to, or nil.
+func (e *ServerError) Unwrap() error {
+	for _, c := range errorCodes {
+		if c.code == e.Code {
+			return c.err
+		}
+	}
+
+	return nil
+}
+
+// Server is an http.Handler that serves a Registry, and stores the schemas
+// registered through it as files in a directory, in the layout that LoadDir
+// reads.
+//
+// Server exposes the following endpoints, where ID is a schema ID such as
+// "billing/invoice@1.0.0", with each of its slash-separated segments escaped as
+// by url.PathEscape:
+//
+//	GET  /schemas                 list the versions of every schema
+//	GET  /schemas/ID              fetch a schema
+//	PUT  /schemas/ID              register a schema
+//	POST /compatibility/ID        check whether a schema could be registered
+//
+// Errors are reported as a JSON ServerError, with a 4xx or 5xx status code.
+// Registering a schema with a version that is already registered is an error:
+// once registered, a schema never changes.
+type Server struct {
+	dir      string
+	registry *Registry
+
+	// mu makes registering schemas, which checks and then writes files, atomic.
+	mu sync.Mutex
+}
+
+// NewServer returns a Server that stores schemas in dir, creating it if needed,
+// and starts out with the schemas that are already there.
+func NewServer(dir string) (*Server, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	r, err := LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{dir: dir, registry: r}, nil
+}
+
+// Registry returns the registry that s serves.
+func (s *Server) Registry() *Registry {
+	return s.registry
+}
+
+// listResponse is the response of GET /schemas.
+type listResponse struct {
+	Schemas map[string][]Version `json:"schemas"`
+}
+
+// idResponse is the response of requests that register or check a schema.
+type idResponse struct {
+	ID string `json:"id"`
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch {
+	case req.URL.Path == "/schemas":
+		if req.Method != http.MethodGet {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+
+		out := listResponse{Schemas: map[string][]Version{}}
+		for _, name := range s.registry.Names() {
+			out.Schemas[name] = s.registry.Versions(name)
+		}
+
+		writeJSON(w, http.StatusOK, out)
+	case strings.HasPrefix(req.URL.Path, "/schemas/"):
+		name, version, err := parseEscapedID(strings.TrimPrefix(req.URL.EscapedPath(), "/schemas/"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		switch req.Method {
+		case http.MethodGet:
+			if _, err := s.registry.Schema(name, version); err != nil {
+				writeError(w, err)
+				return
+			}
+
+			// Serve the file as it was registered, rather than re-encoding the
+			// schema, so that keywords it doesn't have are left out.
+			data, err := os.ReadFile(s.path(name, version))
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(data)
+		case http.MethodPut:
+			schema, data, err := readSchema(w, req)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			if err := s.register(name, version, schema, data); err != nil {
+				writeError(w, err)
+				return
+			}
+
+			writeJSON(w, http.StatusCreated, idResponse{ID: ID(name, version)})
+		default:
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodPut)
+		}
+	case strings.HasPrefix(req.URL.Path, "/compatibility/"):
+		if req.Method != http.MethodPost {
+			writeMethodNotAllowed(w, http.MethodPost)
+			return
+		}
+
+		name, version, err := parseEscapedID(strings.TrimPrefix(req.URL.EscapedPath(), "/compatibility/"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		schema, _, err := readSchema(w, req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := s.registry.CheckAdd(name, version, schema); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, idResponse{ID: ID(name, version)})
+	default:
+		writeJSON(w, http.StatusNotFound, &ServerError{Code: "not_found", Message: "registry: not found"})
+	}
+}
+
+// path returns the path of the file that stores the schema with the given name
+// and version.
+func (s *Server) path(name string, version Version) string {
+	return filepath.Join(s.dir, filepath.FromSlash(name), version.String()+FileExt)
+}
+
+// register writes data, the encoding of schema, to a file in s.dir, and adds
+// schema to s.registry.
+func (s *Server) register(name string, version Version, schema jtd.Schema, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.registry.CheckAdd(name, version, schema); err != nil {
+		return err
+	}
+
+	path := s.path(name, version)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that a failed write never leaves a
+	// partial schema file behind for LoadDir to choke on.
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return s.registry.Add(name, version, schema)
+}
+
+// errInvalidSchema indicates that a request body is not a schema.
+var errInvalidSchema = errors.New("registry: invalid schema")
+
+// readSchema reads the schema in the body of req, and returns it along with
+// the body itself.
+func readSchema(w http.ResponseWriter, req *http.Request) (jtd.Schema, []byte, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxSchemaSize))
+	if err != nil {
+		return jtd.Schema{}, nil, fmt.Errorf("%w: %v", errInvalidSchema, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var schema jtd.Schema
+	if err := decoder.Decode(&schema); err != nil {
+		return jtd.Schema{}, nil, fmt.Errorf("%w: %v", errInvalidSchema, err)
+	}
+
+	return schema, data, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a ServerError. Errors that don't correspond to a
+// code are the client's fault, unless they come from the file system.
+func writeError(w http.ResponseWriter, err error) {
+	out := &ServerError{Code: "invalid_schema", Message: err.Error(), StatusCode: http.StatusBadRequest}
+
+	var pathErr *os.PathError
+	var linkErr *os.LinkError
+	if errors.As(err, &pathErr) || errors.As(err, &linkErr) {
+		out.Code = "internal"
+		out.Message = "registry: internal error"
+		out.StatusCode = http.StatusInternalServerError
+	}
+
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			out.Code = c.code
+			out.StatusCode = c.status
+			break
+		}
+	}
+
+	writeJSON(w, out.StatusCode, out)
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, &ServerError{Code: "method_not_allowed", Message: "registry: method not allowed"})
+}
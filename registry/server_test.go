@@ -0,0 +1,110 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jsontypedef/json-typedef-go/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	server, err := registry.NewServer(dir)
+	assert.NoError(t, err)
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	do := func(method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		data, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		return res.StatusCode, strings.TrimSpace(string(data))
+	}
+
+	code := func(body string) string {
+		var serverErr registry.ServerError
+		assert.NoError(t, json.Unmarshal([]byte(body), &serverErr))
+		return serverErr.Code
+	}
+
+	status, body := do("PUT", "/schemas/a/b@1.0.0", `{"enum": ["X"]}`)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.JSONEq(t, `{"id": "a/b@1.0.0"}`, body)
+
+	// Registered schemas are stored as they were sent.
+	data, err := os.ReadFile(filepath.Join(dir, "a", "b", "1.0.0.jtd.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"enum": ["X"]}`, string(data))
+
+	status, body = do("GET", "/schemas/a/b@1.0.0", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"enum": ["X"]}`, body)
+
+	status, body = do("POST", "/compatibility/a/b@1.1.0", `{"enum": ["X", "Y"]}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"id": "a/b@1.1.0"}`, body)
+
+	status, body = do("POST", "/compatibility/a/b@1.1.0", `{"enum": ["Y"]}`)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "incompatible", code(body))
+
+	// Checking compatibility doesn't register anything.
+	status, _ = do("GET", "/schemas/a/b@1.1.0", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = do("PUT", "/schemas/a/b@2.0.0", `{"type": "string"}`)
+	assert.Equal(t, http.StatusCreated, status)
+
+	status, body = do("GET", "/schemas", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"schemas": {"a/b": ["1.0.0", "2.0.0"]}}`, body)
+
+	errorCases := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"PUT", "/schemas/a/b@1.0.0", `{}`, http.StatusConflict, "duplicate_version"},
+		{"PUT", "/schemas/a/b@1.1.0", `{"enum": ["Y"]}`, http.StatusConflict, "incompatible"},
+		{"PUT", "/schemas/c@1.0.0", `{"type": "foo"}`, http.StatusBadRequest, "invalid_schema"},
+		{"PUT", "/schemas/c@1.0.0", `{"foo": "bar"}`, http.StatusBadRequest, "invalid_schema"},
+		{"PUT", "/schemas/c@1.0.0", `{`, http.StatusBadRequest, "invalid_schema"},
+		{"PUT", "/schemas/c", `{}`, http.StatusBadRequest, "invalid_id"},
+		{"GET", "/schemas/c@1.0.0", "", http.StatusNotFound, "no_such_schema"},
+		{"GET", "/schemas/../c@1.0.0", "", http.StatusBadRequest, "invalid_id"},
+		{"DELETE", "/schemas/a/b@1.0.0", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"POST", "/schemas", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"GET", "/compatibility/a/b@1.0.0", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"GET", "/nonexistent", "", http.StatusNotFound, "not_found"},
+	}
+
+	for _, tt := range errorCases {
+		status, body := do(tt.method, tt.path, tt.body)
+		assert.Equal(t, tt.status, status, "%s %s", tt.method, tt.path)
+		assert.Equal(t, tt.code, code(body), "%s %s", tt.method, tt.path)
+	}
+
+	// A new server picks up the schemas registered through the old one.
+	server, err = registry.NewServer(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []registry.Version{
+		registry.MustParseVersion("1.0.0"),
+		registry.MustParseVersion("2.0.0"),
+	}, server.Registry().Versions("a/b"))
+}
@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVersion indicates that a string is not a valid version.
+var ErrInvalidVersion = errors.New("registry: invalid version")
+
+// Version is a semantic version of a schema, without pre-release or build
+// metadata.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses a version of the form "MAJOR.MINOR.PATCH", such as
+// "1.4.2".
+func ParseVersion(s string) (Version, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		// Leading zeros and signs are not allowed by semantic versioning.
+		if part == "" || (len(part) > 1 && part[0] == '0') || part[0] == '+' || part[0] == '-' {
+			return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+		}
+
+		numbers[i] = n
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// MustParseVersion is like ParseVersion, but panics if s is not a valid
+// version.
+func MustParseVersion(s string) Version {
+	v, err := ParseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// String returns v in the form "MAJOR.MINOR.PATCH".
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// MarshalText implements encoding.TextMarshaler, so that versions are encoded
+// as strings in JSON.
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *Version) UnmarshalText(text []byte) error {
+	parsed, err := ParseVersion(string(text))
+	if err != nil {
+		return err
+	}
+
+	*v = parsed
+	return nil
+}
+
+// Compare returns -1 if v is lower than w, 1 if it is higher, and 0 if they are
+// equal.
+func (v Version) Compare(w Version) int {
+	for _, d := range []int{v.Major - w.Major, v.Minor - w.Minor, v.Patch - w.Patch} {
+		if d < 0 {
+			return -1
+		}
+
+		if d > 0 {
+			return 1
+		}
+	}
+
+	return 0
+}
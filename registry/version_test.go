@@ -0,0 +1,54 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jsontypedef/json-typedef-go/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	v, err := registry.ParseVersion("1.20.3")
+	assert.NoError(t, err)
+	assert.Equal(t, registry.Version{Major: 1, Minor: 20, Patch: 3}, v)
+	assert.Equal(t, "1.20.3", v.String())
+
+	for _, s := range []string{"", "1", "1.2", "1.2.3.4", "01.2.3", "1.-2.3", "1.+2.3", "1.2.x", "1..3", "v1.2.3", "1.2.3-beta"} {
+		_, err := registry.ParseVersion(s)
+		assert.True(t, errors.Is(err, registry.ErrInvalidVersion), s)
+	}
+
+	assert.Panics(t, func() { registry.MustParseVersion("1.2") })
+}
+
+func TestVersionCompare(t *testing.T) {
+	versions := []string{"0.0.1", "0.1.0", "0.1.1", "1.0.0", "1.9.0", "1.10.0", "2.0.0"}
+
+	for i, a := range versions {
+		for j, b := range versions {
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+
+			assert.Equal(t, expected, registry.MustParseVersion(a).Compare(registry.MustParseVersion(b)), "%s %s", a, b)
+		}
+	}
+}
+
+func TestVersionJSON(t *testing.T) {
+	data, err := json.Marshal([]registry.Version{registry.MustParseVersion("1.2.3")})
+	assert.NoError(t, err)
+	assert.Equal(t, `["1.2.3"]`, string(data))
+
+	var v registry.Version
+	assert.NoError(t, json.Unmarshal([]byte(`"4.5.6"`), &v))
+	assert.Equal(t, registry.MustParseVersion("4.5.6"), v)
+
+	err = json.Unmarshal([]byte(`"4.5"`), &v)
+	assert.True(t, errors.Is(err, registry.ErrInvalidVersion))
+}
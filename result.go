@@ -0,0 +1,66 @@
+package jtd
+
+// ValidateResult is the result of ValidateDetailed.
+type ValidateResult struct {
+	// Errors are the errors that Validate would return.
+	Errors []ValidateError
+
+	// Truncated is whether the instance has more errors than the MaxErrors
+	// setting, which were left out of Errors.
+	Truncated bool
+
+	// NodesVisited is the number of times a schema was checked against a part of
+	// the instance.
+	NodesVisited int
+
+	// MaxRefDepth is the largest number of refs that were being followed at
+	// once.
+	MaxRefDepth int
+}
+
+// ValidateDetailed validates a schema against an instance, like Validate, and
+// reports details about the validation alongside the errors.
+//
+// Unlike Validate, ValidateDetailed reports whether the MaxErrors setting
+// caused errors to be left out. To do so, it looks for one more error than
+// MaxErrors before stopping.
+func ValidateDetailed(schema Schema, instance interface{}, opts ...ValidateOption) (ValidateResult, error) {
+	state := getDetailedValidateState(opts)
+	defer putValidateState(state)
+
+	state.Root = schema
+	if err := validate(state, schema, instance, ""); err != nil && err != errMaxErrorsReached {
+		return ValidateResult{}, err
+	}
+
+	return state.detailedResult(), nil
+}
+
+// getDetailedValidateState is like getValidateState, but for ValidateDetailed.
+// It looks for one more error than MaxErrors, to know whether there are more
+// errors than that.
+func getDetailedValidateState(opts []ValidateOption) *validateState {
+	settings := applyValidateOptions(opts)
+	if settings.MaxErrors != 0 {
+		settings.MaxErrors++
+	}
+
+	return getValidateState(settings)
+}
+
+// detailedResult returns the result of a validation that started with
+// getDetailedValidateState.
+func (vs *validateState) detailedResult() ValidateResult {
+	result := ValidateResult{
+		Errors:       vs.errors(),
+		NodesVisited: vs.NodesVisited,
+		MaxRefDepth:  vs.MaxRefDepth,
+	}
+
+	if maxErrors := vs.Settings.MaxErrors; maxErrors != 0 && len(result.Errors) == maxErrors {
+		result.Errors = result.Errors[:maxErrors-1]
+		result.Truncated = true
+	}
+
+	return result
+}
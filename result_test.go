@@ -0,0 +1,81 @@
+package jtd_test
+
+import (
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDetailed(t *testing.T) {
+	schema := jtd.Schema{
+		Elements: &jtd.Schema{Type: jtd.TypeBoolean},
+	}
+
+	instance := []interface{}{nil, nil, nil}
+
+	testCases := []struct {
+		maxErrors int
+		errors    int
+		truncated bool
+	}{
+		{maxErrors: 0, errors: 3, truncated: false},
+		{maxErrors: 2, errors: 2, truncated: true},
+		{maxErrors: 3, errors: 3, truncated: false},
+		{maxErrors: 4, errors: 3, truncated: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(fmt.Sprint(tt.maxErrors), func(t *testing.T) {
+			result, err := jtd.ValidateDetailed(schema, instance, jtd.WithMaxErrors(tt.maxErrors))
+			assert.NoError(t, err)
+			assert.Len(t, result.Errors, tt.errors)
+			assert.Equal(t, tt.truncated, result.Truncated)
+
+			// Validate returns the same errors.
+			errs, err := jtd.Validate(schema, instance, jtd.WithMaxErrors(tt.maxErrors))
+			assert.NoError(t, err)
+			assert.Equal(t, errs, result.Errors)
+		})
+	}
+}
+
+func TestValidateDetailedStats(t *testing.T) {
+	node := "node"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"node": jtd.Schema{
+				Elements: &jtd.Schema{Ref: &node},
+			},
+		},
+		Ref: &node,
+	}
+
+	result, err := jtd.ValidateDetailed(schema, []interface{}{
+		[]interface{}{[]interface{}{}},
+		[]interface{}{},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.False(t, result.Truncated)
+
+	// Each of the four arrays is checked against a ref, and against the
+	// definition it refers to.
+	assert.Equal(t, 8, result.NodesVisited)
+	assert.Equal(t, 3, result.MaxRefDepth)
+
+	_, err = jtd.ValidateDetailed(schema, []interface{}{[]interface{}{}}, jtd.WithMaxDepth(1))
+	assert.Equal(t, jtd.ErrMaxDepthExceeded, err)
+}
+
+func ExampleValidateDetailed() {
+	schema := jtd.Schema{
+		Elements: &jtd.Schema{Type: jtd.TypeString},
+	}
+
+	result, _ := jtd.ValidateDetailed(schema, []interface{}{1.0, 2.0, 3.0}, jtd.WithMaxErrors(2))
+	fmt.Println(len(result.Errors), result.Truncated)
+	// Output:
+	// 2 true
+}
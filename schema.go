@@ -2,7 +2,7 @@ package jtd
 
 import (
 	"errors"
-	"reflect"
+	"sort"
 )
 
 // Schema represents a JSON Typedef Schema.
@@ -101,48 +101,181 @@ var ErrMappingRepeatedDiscriminator = errors.New("jtd: mapping re-specifies disc
 // "nullable" set to true.
 var ErrNullableMapping = errors.New("jtd: mapping allows for nullable values")
 
+// ErrInvalidDefault indicates that a schema has a "default" in its metadata
+// that is not a valid instance of the schema.
+var ErrInvalidDefault = errors.New("jtd: default not valid against its schema")
+
 // Validate returns an error if a schema is not a valid root JSON Typedef
 // schema.
 //
 // Validate may return one of ErrInvalidForm, ErrNonRootDefinition,
 // ErrNoSuchDefinition, ErrInvalidType, ErrEmptyEnum, ErrRepeatedEnumValue,
 // ErrSharedProperty, ErrNonPropertiesMapping, ErrMappingRepeatedDiscriminator,
-// or ErrNullableMapping.
+// ErrNullableMapping, or ErrInvalidDefault. Defaults are checked only once the
+// rest of the schema is known to be valid, and checking one returns
+// ErrUnproductiveRefCycle if it would follow refs forever.
 func (s Schema) Validate() error {
 	return s.ValidateWithRoot(true, s)
 }
 
-// Index of valid form "signatures" -- i.e., combinations of the presence of the
-// keywords (in order):
+// RefCycles returns the unproductive ref cycles in s, a root schema.
 //
-// ref type enum elements properties optionalProperties additionalProperties
-// values discriminator mapping
+// An unproductive ref cycle is a set of definitions that are each of the ref
+// form, and that refer to each other in a loop. Validating an instance against
+// such definitions follows refs forever without consuming any input, unless the
+// instance is null and one of the definitions is nullable. Cycles that contain a
+// nullable definition are still returned, since they can only be satisfied by
+// null.
 //
-// The keywords "definitions", "nullable", and "metadata" are not included here,
-// because they would restrict nothing.
-var validForms = [][]bool{
-	// Empty form
-	{false, false, false, false, false, false, false, false, false, false},
-	// Ref form
-	{true, false, false, false, false, false, false, false, false, false},
-	// Type form
-	{false, true, false, false, false, false, false, false, false, false},
-	// Enum form
-	{false, false, true, false, false, false, false, false, false, false},
-	// Elements form
-	{false, false, false, true, false, false, false, false, false, false},
+// Each cycle is returned as the names of its definitions, in the order in which
+// they refer to each other, starting from the lexicographically smallest name.
+// Cycles are returned in order of their first name. If s has no unproductive
+// ref cycles, RefCycles returns nil.
+func (s Schema) RefCycles() [][]string {
+	var cycles [][]string
+
+	// done contains the definitions already known to be, or to lead into, a
+	// cycle that has been reported, or to lead nowhere.
+	done := map[string]bool{}
+	for _, name := range sortedKeys(s.Definitions) {
+		// Follow the chain of refs from name, until reaching either a definition
+		// that isn't of the ref form, a definition that's done, or a definition
+		// already on this chain.
+		var chain []string
+		onChain := map[string]int{}
+
+		for next := name; ; {
+			if done[next] {
+				break
+			}
+
+			if i, ok := onChain[next]; ok {
+				cycle := chain[i:]
+
+				start := 0
+				for j, name := range cycle {
+					if name < cycle[start] {
+						start = j
+					}
+				}
+
+				cycles = append(cycles, append(cycle[start:len(cycle):len(cycle)], cycle[:start]...))
+				break
+			}
+
+			onChain[next] = len(chain)
+			chain = append(chain, next)
+
+			def, ok := s.Definitions[next]
+			if !ok || def.Form() != FormRef {
+				break
+			}
+
+			next = *def.Ref
+		}
+
+		for _, name := range chain {
+			done[name] = true
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+
+	return cycles
+}
+
+// Bits for the keywords that determine the form of a schema. The keywords
+// "definitions", "nullable", and "metadata" are not included here, because they
+// would restrict nothing.
+const (
+	keywordRef uint16 = 1 << iota
+	keywordType
+	keywordEnum
+	keywordElements
+	keywordProperties
+	keywordOptionalProperties
+	keywordAdditionalProperties
+	keywordValues
+	keywordDiscriminator
+	keywordMapping
+)
+
+// keywords returns the set of form-determining keywords present in s.
+func (s Schema) keywords() uint16 {
+	var k uint16
+	if s.Ref != nil {
+		k |= keywordRef
+	}
+
+	if s.Type != "" {
+		k |= keywordType
+	}
+
+	if s.Enum != nil {
+		k |= keywordEnum
+	}
+
+	if s.Elements != nil {
+		k |= keywordElements
+	}
+
+	if s.Properties != nil {
+		k |= keywordProperties
+	}
+
+	if s.OptionalProperties != nil {
+		k |= keywordOptionalProperties
+	}
+
+	if s.AdditionalProperties {
+		k |= keywordAdditionalProperties
+	}
+
+	if s.Values != nil {
+		k |= keywordValues
+	}
+
+	if s.Discriminator != "" {
+		k |= keywordDiscriminator
+	}
+
+	if s.Mapping != nil {
+		k |= keywordMapping
+	}
+
+	return k
+}
+
+// formOfKeywords returns the form of a schema with the given keywords, or false
+// if they are not a valid combination.
+func formOfKeywords(k uint16) (Form, bool) {
+	switch k {
+	case 0:
+		return FormEmpty, true
+	case keywordRef:
+		return FormRef, true
+	case keywordType:
+		return FormType, true
+	case keywordEnum:
+		return FormEnum, true
+	case keywordElements:
+		return FormElements, true
+	case keywordValues:
+		return FormValues, true
+	case keywordDiscriminator | keywordMapping:
+		return FormDiscriminator, true
+	}
+
 	// Properties form -- properties or optional properties or both, and never
-	// additional properties on its own
-	{false, false, false, false, true, false, false, false, false, false},
-	{false, false, false, false, false, true, false, false, false, false},
-	{false, false, false, false, true, true, false, false, false, false},
-	{false, false, false, false, true, false, true, false, false, false},
-	{false, false, false, false, false, true, true, false, false, false},
-	{false, false, false, false, true, true, true, false, false, false},
-	// Values form
-	{false, false, false, false, false, false, false, true, false, false},
-	// Discriminator form
-	{false, false, false, false, false, false, false, false, true, true},
+	// additional properties on its own.
+	if k&(keywordProperties|keywordOptionalProperties) != 0 &&
+		k&^(keywordProperties|keywordOptionalProperties|keywordAdditionalProperties) == 0 {
+		return FormProperties, true
+	}
+
+	return "", false
 }
 
 // ValidateWithRoot returns an error if s is not a valid schema, given the root
@@ -151,26 +284,24 @@ var validForms = [][]bool{
 // isRoot indicates whether the schema is expected to be a root schema. root is
 // the root schema s is supposed to be contained within. If isRoot is true, then
 // root should be equal to s for the return value to be meaningful.
+//
+// Defaults in metadata are checked only if isRoot is true, after the rest of s
+// is known to be valid, since checking them means validating against root.
 func (s Schema) ValidateWithRoot(isRoot bool, root Schema) error {
-	formSignature := []bool{
-		s.Ref != nil,
-		s.Type != "",
-		s.Enum != nil,
-		s.Elements != nil,
-		s.Properties != nil,
-		s.OptionalProperties != nil,
-		s.AdditionalProperties,
-		s.Values != nil,
-		s.Discriminator != "",
-		s.Mapping != nil,
+	if err := s.validateWithRoot(isRoot, root); err != nil {
+		return err
 	}
 
-	formOk := false
-	for _, form := range validForms {
-		formOk = formOk || reflect.DeepEqual(formSignature, form)
+	if isRoot {
+		return s.validateDefaults(root, "", "")
 	}
 
-	if !formOk {
+	return nil
+}
+
+// validateWithRoot is ValidateWithRoot, without checking defaults.
+func (s Schema) validateWithRoot(isRoot bool, root Schema) error {
+	if _, ok := formOfKeywords(s.keywords()); !ok {
 		return ErrInvalidForm
 	}
 
@@ -179,7 +310,7 @@ func (s Schema) ValidateWithRoot(isRoot bool, root Schema) error {
 	}
 
 	for _, s := range s.Definitions {
-		if err := s.ValidateWithRoot(false, root); err != nil {
+		if err := s.validateWithRoot(false, root); err != nil {
 			return err
 		}
 	}
@@ -195,28 +326,10 @@ func (s Schema) ValidateWithRoot(isRoot bool, root Schema) error {
 	}
 
 	if s.Type != "" {
-		validTypes := []Type{
-			TypeBoolean,
-			TypeFloat32,
-			TypeFloat64,
-			TypeInt8,
-			TypeUint8,
-			TypeInt16,
-			TypeUint16,
-			TypeInt32,
-			TypeUint32,
-			TypeString,
-			TypeTimestamp,
-		}
-
-		ok := false
-		for _, t := range validTypes {
-			if s.Type == t {
-				ok = true
-			}
-		}
-
-		if !ok {
+		switch s.Type {
+		case TypeBoolean, TypeFloat32, TypeFloat64, TypeInt8, TypeUint8, TypeInt16,
+			TypeUint16, TypeInt32, TypeUint32, TypeString, TypeTimestamp:
+		default:
 			return ErrInvalidType
 		}
 	}
@@ -237,13 +350,13 @@ func (s Schema) ValidateWithRoot(isRoot bool, root Schema) error {
 	}
 
 	if s.Elements != nil {
-		if err := s.Elements.ValidateWithRoot(false, root); err != nil {
+		if err := s.Elements.validateWithRoot(false, root); err != nil {
 			return err
 		}
 	}
 
 	for k, p := range s.Properties {
-		if err := p.ValidateWithRoot(false, root); err != nil {
+		if err := p.validateWithRoot(false, root); err != nil {
 			return err
 		}
 
@@ -255,19 +368,19 @@ func (s Schema) ValidateWithRoot(isRoot bool, root Schema) error {
 	}
 
 	for _, s := range s.OptionalProperties {
-		if err := s.ValidateWithRoot(false, root); err != nil {
+		if err := s.validateWithRoot(false, root); err != nil {
 			return err
 		}
 	}
 
 	if s.Values != nil {
-		if err := s.Values.ValidateWithRoot(false, root); err != nil {
+		if err := s.Values.validateWithRoot(false, root); err != nil {
 			return err
 		}
 	}
 
 	for _, m := range s.Mapping {
-		if err := m.ValidateWithRoot(false, root); err != nil {
+		if err := m.validateWithRoot(false, root); err != nil {
 			return err
 		}
 
@@ -295,33 +408,93 @@ func (s Schema) ValidateWithRoot(isRoot bool, root Schema) error {
 	return nil
 }
 
-// Form returns JSON Typedef schema form that s takes on.
-func (s Schema) Form() Form {
-	if s.Ref != nil {
-		return FormRef
-	}
+// validateDefaults returns ErrInvalidDefault if the default in the metadata of
+// s, or of any of its subschemas and definitions, is not valid against the
+// schema it's in. See ApplyDefaults. root must be a valid root schema.
+//
+// If s is a mapping of a discriminator, discriminator is the discriminator's
+// property and tag is the value of it that s is mapped to. Instances of a
+// mapping have the discriminator's property set to its tag, which the mapping
+// itself doesn't allow. So defaults of mappings are validated against the
+// discriminator instead, restricted to that mapping.
+func (s Schema) validateDefaults(root Schema, discriminator, tag string) error {
+	if v, ok := s.Default(); ok {
+		schema := s
+		if discriminator != "" {
+			schema = Schema{Discriminator: discriminator, Mapping: map[string]Schema{tag: s}}
+		}
 
-	if s.Type != "" {
-		return FormType
+		schema.Definitions = root.Definitions
+
+		errs, err := Validate(schema, v)
+		if err != nil {
+			return err
+		}
+
+		if len(errs) != 0 {
+			return ErrInvalidDefault
+		}
 	}
 
-	if s.Enum != nil {
-		return FormEnum
+	for _, name := range sortedKeys(s.Definitions) {
+		if err := s.Definitions[name].validateDefaults(root, "", ""); err != nil {
+			return err
+		}
 	}
 
 	if s.Elements != nil {
-		return FormElements
+		if err := s.Elements.validateDefaults(root, "", ""); err != nil {
+			return err
+		}
 	}
 
-	if s.Properties != nil || s.OptionalProperties != nil {
-		return FormProperties
+	for _, properties := range []map[string]Schema{s.Properties, s.OptionalProperties} {
+		for _, key := range sortedKeys(properties) {
+			if err := properties[key].validateDefaults(root, "", ""); err != nil {
+				return err
+			}
+		}
 	}
 
 	if s.Values != nil {
-		return FormValues
+		if err := s.Values.validateDefaults(root, "", ""); err != nil {
+			return err
+		}
 	}
 
-	if s.Mapping != nil {
+	for _, tag := range sortedKeys(s.Mapping) {
+		if err := s.Mapping[tag].validateDefaults(root, s.Discriminator, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Form returns JSON Typedef schema form that s takes on.
+//
+// If s uses an invalid combination of keywords, Form returns the form of the
+// first of them, in the order in which the forms are declared.
+func (s Schema) Form() Form {
+	k := s.keywords()
+	if form, ok := formOfKeywords(k); ok {
+		return form
+	}
+
+	switch {
+	case k&keywordRef != 0:
+		return FormRef
+	case k&keywordType != 0:
+		return FormType
+	case k&keywordEnum != 0:
+		return FormEnum
+	case k&keywordElements != 0:
+		return FormElements
+	case k&(keywordProperties|keywordOptionalProperties) != 0:
+		return FormProperties
+	case k&keywordValues != 0:
+		return FormValues
+	case k&keywordMapping != 0:
 		return FormDiscriminator
 	}
 
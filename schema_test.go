@@ -38,3 +38,70 @@ func TestInvalidSchemas(t *testing.T) {
 		})
 	}
 }
+
+func TestForm(t *testing.T) {
+	a := "a"
+	for _, tt := range []struct {
+		schema jtd.Schema
+		form   jtd.Form
+	}{
+		{jtd.Schema{}, jtd.FormEmpty},
+		{jtd.Schema{Nullable: true, Metadata: map[string]interface{}{}}, jtd.FormEmpty},
+		{jtd.Schema{Ref: &a}, jtd.FormRef},
+		{jtd.Schema{Type: jtd.TypeString}, jtd.FormType},
+		{jtd.Schema{Enum: []string{"a"}}, jtd.FormEnum},
+		{jtd.Schema{Elements: &jtd.Schema{}}, jtd.FormElements},
+		{jtd.Schema{Properties: map[string]jtd.Schema{}}, jtd.FormProperties},
+		{jtd.Schema{OptionalProperties: map[string]jtd.Schema{}, AdditionalProperties: true}, jtd.FormProperties},
+		{jtd.Schema{Values: &jtd.Schema{}}, jtd.FormValues},
+		{jtd.Schema{Discriminator: "a", Mapping: map[string]jtd.Schema{}}, jtd.FormDiscriminator},
+
+		// Invalid combinations of keywords take on the form of the first one.
+		{jtd.Schema{Ref: &a, Type: jtd.TypeString}, jtd.FormRef},
+		{jtd.Schema{Enum: []string{"a"}, Values: &jtd.Schema{}}, jtd.FormEnum},
+		{jtd.Schema{Mapping: map[string]jtd.Schema{}}, jtd.FormDiscriminator},
+		{jtd.Schema{Discriminator: "a"}, jtd.FormEmpty},
+		{jtd.Schema{AdditionalProperties: true}, jtd.FormEmpty},
+	} {
+		assert.Equal(t, tt.form, tt.schema.Form(), "%+v", tt.schema)
+	}
+}
+
+func TestRefCycles(t *testing.T) {
+	ref := func(s string) *string { return &s }
+
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"a":    jtd.Schema{Ref: ref("c")},
+			"b":    jtd.Schema{Ref: ref("a")},
+			"c":    jtd.Schema{Ref: ref("b")},
+			"d":    jtd.Schema{Ref: ref("d")},
+			"e":    jtd.Schema{Ref: ref("d")},
+			"f":    jtd.Schema{Ref: ref("g")},
+			"g":    jtd.Schema{Type: jtd.TypeString},
+			"tree": jtd.Schema{Elements: &jtd.Schema{Ref: ref("tree")}},
+		},
+	}
+
+	assert.NoError(t, schema.Validate())
+	assert.Equal(t, [][]string{{"a", "c", "b"}, {"d"}}, schema.RefCycles())
+	assert.Nil(t, jtd.Schema{}.RefCycles())
+
+	// A nullable definition lets null satisfy the cycle, but nothing else.
+	schema = jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"a": jtd.Schema{Ref: ref("b"), Nullable: true},
+			"b": jtd.Schema{Ref: ref("a")},
+		},
+		Ref: ref("a"),
+	}
+
+	assert.Equal(t, [][]string{{"a", "b"}}, schema.RefCycles())
+
+	errs, err := jtd.Validate(schema, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+
+	_, err = jtd.Validate(schema, "a")
+	assert.Equal(t, jtd.ErrUnproductiveRefCycle, err)
+}
@@ -0,0 +1,290 @@
+// Package tsgen generates TypeScript type declarations from JSON Typedef
+// schemas.
+//
+// Schemas are mapped to TypeScript as follows:
+//
+//	empty            unknown
+//	boolean          boolean
+//	float32 ... int  number
+//	string           string
+//	timestamp        string
+//	enum             union of string literals, e.g. "A" | "B"
+//	elements         T[]
+//	properties       interface, with "?" on optional properties
+//	values           Record<string, T>
+//	discriminator    union of interfaces, one per mapping value
+//	ref              the named type of the definition
+//
+// A nullable schema of type T becomes T | null. Each definition becomes an
+// exported named type, and so does the root schema, unless it is of the empty
+// form and has definitions. Names are converted to PascalCase, and the
+// "description" metadata of schemas is emitted as doc comments.
+package tsgen
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/internal/schemautil"
+)
+
+// Generate writes TypeScript declarations for schema to w. rootName is the name
+// of the type for the root schema.
+func Generate(w io.Writer, schema jtd.Schema, rootName string) error {
+	g := generator{names: map[string]string{}, used: map[string]bool{}}
+
+	hasRoot := schema.Form() != jtd.FormEmpty || len(schema.Definitions) == 0
+	if hasRoot {
+		g.used[typeName(rootName)] = true
+	}
+
+	for _, name := range schemautil.SortedKeys(schema.Definitions) {
+		g.names[name] = g.uniqueName(typeName(name))
+	}
+
+	if hasRoot {
+		g.declare(typeName(rootName), schema)
+	}
+
+	for _, name := range schemautil.SortedKeys(schema.Definitions) {
+		g.declare(g.names[name], schema.Definitions[name])
+	}
+
+	_, err := io.WriteString(w, strings.TrimPrefix(g.b.String(), "\n"))
+	return err
+}
+
+type generator struct {
+	// names maps definition names to the TypeScript names of their types.
+	names map[string]string
+
+	// used is the set of TypeScript names already taken.
+	used map[string]bool
+
+	b strings.Builder
+}
+
+// declare writes an exported declaration of a type called name for s.
+func (g *generator) declare(name string, s jtd.Schema) {
+	g.b.WriteString("\n")
+	g.comment("", s)
+
+	switch {
+	case s.Form() == jtd.FormProperties && !s.Nullable:
+		fmt.Fprintf(&g.b, "export interface %s %s\n", name, g.object("", nil, s))
+	case s.Form() == jtd.FormDiscriminator:
+		var variants []string
+		for _, tag := range schemautil.SortedKeys(s.Mapping) {
+			variants = append(variants, g.uniqueName(name+typeName(tag)))
+		}
+
+		union := append([]string{}, variants...)
+		if len(union) == 0 {
+			union = append(union, "never")
+		}
+
+		if s.Nullable {
+			union = append(union, "null")
+		}
+
+		fmt.Fprintf(&g.b, "export type %s = %s;\n", name, strings.Join(union, " | "))
+
+		for i, tag := range schemautil.SortedKeys(s.Mapping) {
+			mapping := s.Mapping[tag]
+
+			g.b.WriteString("\n")
+			g.comment("", mapping)
+			fmt.Fprintf(&g.b, "export interface %s %s\n", variants[i], g.object("", &tagProperty{s.Discriminator, tag}, mapping))
+		}
+	default:
+		fmt.Fprintf(&g.b, "export type %s = %s;\n", name, g.expr("", s))
+	}
+}
+
+type tagProperty struct {
+	name  string
+	value string
+}
+
+// expr returns a TypeScript type expression for s, indented by indent.
+func (g *generator) expr(indent string, s jtd.Schema) string {
+	var t string
+	switch s.Form() {
+	case jtd.FormRef:
+		t = g.names[*s.Ref]
+	case jtd.FormType:
+		switch s.Type {
+		case jtd.TypeBoolean:
+			t = "boolean"
+		case jtd.TypeString, jtd.TypeTimestamp:
+			t = "string"
+		default:
+			t = "number"
+		}
+	case jtd.FormEnum:
+		var values []string
+		for _, value := range s.Enum {
+			values = append(values, strconv.Quote(value))
+		}
+
+		t = strings.Join(values, " | ")
+	case jtd.FormElements:
+		elements := g.expr(indent, *s.Elements)
+		if isUnion(*s.Elements) {
+			elements = "(" + elements + ")"
+		}
+
+		t = elements + "[]"
+	case jtd.FormProperties:
+		t = g.object(indent, nil, s)
+	case jtd.FormValues:
+		t = "Record<string, " + g.expr(indent, *s.Values) + ">"
+	case jtd.FormDiscriminator:
+		var variants []string
+		for _, tag := range schemautil.SortedKeys(s.Mapping) {
+			variants = append(variants, g.object(indent, &tagProperty{s.Discriminator, tag}, s.Mapping[tag]))
+		}
+
+		if len(variants) == 0 {
+			variants = append(variants, "never")
+		}
+
+		t = strings.Join(variants, " | ")
+	default:
+		t = "unknown"
+	}
+
+	if s.Nullable && t != "unknown" {
+		t += " | null"
+	}
+
+	return t
+}
+
+// isUnion returns whether the type expression for s is a union type.
+func isUnion(s jtd.Schema) bool {
+	switch s.Form() {
+	case jtd.FormEmpty:
+		return false
+	case jtd.FormEnum:
+		return s.Nullable || len(s.Enum) > 1
+	case jtd.FormDiscriminator:
+		return s.Nullable || len(s.Mapping) > 1
+	default:
+		return s.Nullable
+	}
+}
+
+// object returns an object type literal for s, a schema of the properties
+// form, indented by indent. If tag is not nil, it is added as the first
+// property of the object.
+func (g *generator) object(indent string, tag *tagProperty, s jtd.Schema) string {
+	inner := indent + "  "
+
+	var b strings.Builder
+	b.WriteString("{\n")
+
+	if tag != nil {
+		fmt.Fprintf(&b, "%s%s: %s;\n", inner, propertyName(tag.name), strconv.Quote(tag.value))
+	}
+
+	for _, required := range []bool{true, false} {
+		properties, optional := s.Properties, ""
+		if !required {
+			properties, optional = s.OptionalProperties, "?"
+		}
+
+		for _, name := range schemautil.SortedKeys(properties) {
+			b.WriteString(comment(inner, properties[name]))
+			fmt.Fprintf(&b, "%s%s%s: %s;\n", inner, propertyName(name), optional, g.expr(inner, properties[name]))
+		}
+	}
+
+	if s.AdditionalProperties {
+		fmt.Fprintf(&b, "%s[key: string]: unknown;\n", inner)
+	}
+
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+func (g *generator) comment(indent string, s jtd.Schema) {
+	g.b.WriteString(comment(indent, s))
+}
+
+// comment returns a doc comment for s, indented by indent, or an empty string
+// if s has no description.
+func comment(indent string, s jtd.Schema) string {
+	description := s.Description()
+	if description == "" {
+		return ""
+	}
+
+	description = strings.Replace(description, "*/", "*\\/", -1)
+	lines := strings.Split(description, "\n")
+	if len(lines) == 1 {
+		return indent + "/** " + lines[0] + " */\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		b.WriteString(strings.TrimRight(indent+" * "+line, " ") + "\n")
+	}
+	b.WriteString(indent + " */\n")
+
+	return b.String()
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func propertyName(name string) string {
+	if identifier.MatchString(name) {
+		return name
+	}
+
+	return strconv.Quote(name)
+}
+
+// typeName converts name to a PascalCase TypeScript identifier.
+func typeName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	out := b.String()
+	if out == "" || unicode.IsDigit(rune(out[0])) {
+		out = "T" + out
+	}
+
+	return out
+}
+
+// uniqueName returns name, or name followed by a number if name is already
+// taken, and records the returned name as taken.
+func (g *generator) uniqueName(name string) string {
+	out := name
+	for i := 2; g.used[out]; i++ {
+		out = name + strconv.Itoa(i)
+	}
+
+	g.used[out] = true
+	return out
+}
@@ -0,0 +1,135 @@
+package tsgen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/jsontypedef/json-typedef-go/tsgen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"event": {
+				"discriminator": "type",
+				"mapping": {
+					"user_created": {
+						"properties": { "id": { "type": "string" } }
+					},
+					"user_deleted": {
+						"properties": {},
+						"optionalProperties": { "reason": { "type": "string", "nullable": true } }
+					}
+				}
+			},
+			"status": {
+				"metadata": { "description": "The status.\nEither active or inactive." },
+				"enum": ["ACTIVE", "INACTIVE"]
+			},
+			"common.money": {
+				"properties": {
+					"amount": { "type": "float64" },
+					"extra": {}
+				},
+				"additionalProperties": true
+			}
+		},
+		"properties": {
+			"statuses": { "elements": { "ref": "status", "nullable": true } },
+			"counts": { "values": { "type": "uint32" } },
+			"created-at": { "type": "timestamp" },
+			"shape": {
+				"discriminator": "kind",
+				"mapping": {
+					"square": { "properties": { "size": { "type": "int8" } } }
+				}
+			}
+		},
+		"optionalProperties": {
+			"flag": { "type": "boolean" },
+			"price": { "ref": "common.money" }
+		}
+	}`), &schema))
+	assert.NoError(t, schema.Validate())
+
+	var b bytes.Buffer
+	assert.NoError(t, tsgen.Generate(&b, schema, "order"))
+	assert.Equal(t, `export interface Order {
+  counts: Record<string, number>;
+  "created-at": string;
+  shape: {
+    kind: "square";
+    size: number;
+  };
+  statuses: (Status | null)[];
+  flag?: boolean;
+  price?: CommonMoney;
+}
+
+export interface CommonMoney {
+  amount: number;
+  extra: unknown;
+  [key: string]: unknown;
+}
+
+export type Event = EventUserCreated | EventUserDeleted;
+
+export interface EventUserCreated {
+  type: "user_created";
+  id: string;
+}
+
+export interface EventUserDeleted {
+  type: "user_deleted";
+  reason?: string | null;
+}
+
+/**
+ * The status.
+ * Either active or inactive.
+ */
+export type Status = "ACTIVE" | "INACTIVE";
+`, b.String())
+}
+
+func TestGenerateLibrary(t *testing.T) {
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"id":       jtd.Schema{Type: jtd.TypeString},
+			"Id":       jtd.Schema{Type: jtd.TypeUint32, Nullable: true},
+			"anything": jtd.Schema{Nullable: true},
+		},
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, tsgen.Generate(&b, schema, "root"))
+	assert.Equal(t, `export type Id = number | null;
+
+export type Anything = unknown;
+
+export type Id2 = string;
+`, b.String())
+}
+
+func ExampleGenerate() {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"name": jtd.Schema{Type: jtd.TypeString},
+		},
+		OptionalProperties: map[string]jtd.Schema{
+			"age": jtd.Schema{Type: jtd.TypeUint8, Nullable: true},
+		},
+	}
+
+	tsgen.Generate(os.Stdout, schema, "user")
+	// Output:
+	// export interface User {
+	//   name: string;
+	//   age?: number | null;
+	// }
+}
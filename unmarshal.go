@@ -0,0 +1,68 @@
+package jtd
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Unmarshal parses the JSON-encoded data, validates it against schema, and
+// stores the result in the value pointed to by v only if it is valid.
+//
+// If the data is not valid, Unmarshal returns the ValidateError that Validate
+// would return for it, and leaves v untouched. Unmarshal returns an error if
+// data is not valid JSON, if v is not a non-nil pointer, or if Validate returns
+// an error.
+//
+// Data is always decoded into an interface{} first, to validate it. If v is a
+// *interface{}, *map[string]interface{}, or *[]interface{}, that is the only
+// decode. For any other type, including structs, valid data is decoded a second
+// time, so Unmarshal costs about as much as two calls to json.Unmarshal. The
+// second decode goes into a new value of the type v points to, so that it gets
+// populated with the same semantics as json.Unmarshal, including struct tags
+// and json.Unmarshaler. That value is stored in v only if decoding it succeeds,
+// so unlike json.Unmarshal, Unmarshal never leaves v partly populated, and
+// replaces what v points to rather than merging data into it.
+func Unmarshal(schema Schema, data []byte, v interface{}, opts ...ValidateOption) ([]ValidateError, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, &json.InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+
+	var instance interface{}
+	if err := json.Unmarshal(data, &instance); err != nil {
+		return nil, err
+	}
+
+	errs, err := Validate(schema, instance, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(errs) > 0 {
+		return errs, nil
+	}
+
+	switch v := v.(type) {
+	case *interface{}:
+		*v = instance
+		return errs, nil
+	case *map[string]interface{}:
+		if obj, ok := instance.(map[string]interface{}); ok {
+			*v = obj
+			return errs, nil
+		}
+	case *[]interface{}:
+		if arr, ok := instance.([]interface{}); ok {
+			*v = arr
+			return errs, nil
+		}
+	}
+
+	out := reflect.New(rv.Elem().Type())
+	if err := json.Unmarshal(data, out.Interface()); err != nil {
+		return nil, err
+	}
+
+	rv.Elem().Set(out.Elem())
+	return errs, nil
+}
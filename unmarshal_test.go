@@ -0,0 +1,106 @@
+package jtd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type unmarshalUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+var unmarshalSchema = jtd.Schema{
+	Properties: map[string]jtd.Schema{
+		"id":   jtd.Schema{Type: jtd.TypeUint32},
+		"name": jtd.Schema{Type: jtd.TypeString},
+	},
+}
+
+func TestUnmarshal(t *testing.T) {
+	var user unmarshalUser
+	errs, err := jtd.Unmarshal(unmarshalSchema, []byte(`{"id": 42, "name": "a"}`), &user)
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, unmarshalUser{ID: 42, Name: "a"}, user)
+
+	var generic interface{}
+	errs, err = jtd.Unmarshal(unmarshalSchema, []byte(`{"id": 42, "name": "a"}`), &generic)
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]interface{}{"id": 42.0, "name": "a"}, generic)
+
+	var obj map[string]interface{}
+	errs, err = jtd.Unmarshal(unmarshalSchema, []byte(`{"id": 42, "name": "a"}`), &obj)
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]interface{}{"id": 42.0, "name": "a"}, obj)
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	user := unmarshalUser{ID: 1}
+	errs, err := jtd.Unmarshal(unmarshalSchema, []byte(`{"id": -42, "name": "a"}`), &user)
+	assert.NoError(t, err)
+	assert.Equal(t, []jtd.ValidateError{{
+		InstancePath: []string{"id"},
+		SchemaPath:   []string{"properties", "id", "type"},
+	}}, errs)
+
+	// v is left untouched.
+	assert.Equal(t, unmarshalUser{ID: 1}, user)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var user unmarshalUser
+
+	_, err := jtd.Unmarshal(unmarshalSchema, []byte(`{"id": `), &user)
+	assert.IsType(t, &json.SyntaxError{}, err)
+
+	_, err = jtd.Unmarshal(unmarshalSchema, []byte(`{"id": 42, "name": "a"}`), user)
+	assert.IsType(t, &json.InvalidUnmarshalError{}, err)
+
+	// Data that is valid against the schema, but can't be decoded into v, leaves
+	// v untouched.
+	var small struct {
+		ID   int8   `json:"id"`
+		Name string `json:"name"`
+	}
+
+	_, err = jtd.Unmarshal(unmarshalSchema, []byte(`{"name": "a", "id": 200}`), &small)
+	assert.IsType(t, &json.UnmarshalTypeError{}, err)
+	assert.Empty(t, small.Name)
+
+	loop := "loop"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{"loop": jtd.Schema{Ref: &loop}},
+		Ref:         &loop,
+	}
+
+	_, err = jtd.Unmarshal(schema, []byte(`{}`), &user, jtd.WithMaxDepth(32))
+	assert.Equal(t, jtd.ErrMaxDepthExceeded, err)
+}
+
+func ExampleUnmarshal() {
+	type User struct {
+		Name string `json:"name"`
+	}
+
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"name": jtd.Schema{Type: jtd.TypeString},
+		},
+	}
+
+	var user User
+	fmt.Println(jtd.Unmarshal(schema, []byte(`{"name": "a"}`), &user))
+	fmt.Println(user.Name)
+	fmt.Println(jtd.Unmarshal(schema, []byte(`{"name": 1}`), &user))
+	// Output:
+	// [] <nil>
+	// a
+	// [{[name] [properties name type]}] <nil>
+}
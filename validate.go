@@ -2,8 +2,10 @@ package jtd
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -11,6 +13,9 @@ import (
 type ValidateSettings struct {
 	// The maximum number of refs to recursively follow before returning
 	// ErrMaxDepthExceeded. Zero disables a max depth altogether.
+	//
+	// When MaxDepth is zero, Validate instead returns ErrUnproductiveRefCycle if
+	// it would otherwise follow refs forever without consuming any input.
 	MaxDepth int
 
 	// The maximum number of validation errors to return. Zero disables a max
@@ -35,6 +40,22 @@ func WithMaxErrors(maxErrors int) ValidateOption {
 	}
 }
 
+// applyValidateOptions returns the settings that opts result in.
+func applyValidateOptions(opts []ValidateOption) ValidateSettings {
+	// The settings escape to the heap when passed to opts, so avoid that when
+	// there are no options.
+	if len(opts) == 0 {
+		return ValidateSettings{}
+	}
+
+	settings := &ValidateSettings{}
+	for _, opt := range opts {
+		opt(settings)
+	}
+
+	return *settings
+}
+
 // ValidateError is a validation error returned from Validate.
 //
 // This corresponds to a standard error indicator from the JSON Typedef
@@ -54,46 +75,100 @@ type ValidateError struct {
 // ValidateSettings.
 var ErrMaxDepthExceeded = errors.New("jtd: max depth exceeded")
 
+// ErrUnproductiveRefCycle is the error returned from Validate if it would
+// otherwise follow refs forever without consuming any input.
+//
+// This can only happen with schemas that have unproductive ref cycles; see
+// Schema.RefCycles.
+var ErrUnproductiveRefCycle = errors.New("jtd: unproductive ref cycle")
+
 // Validate validates a schema against an instance (or "input").
 //
 // Returns ErrMaxDepthExceeded if too many refs are recursively followed while
-// validating. Otherwise, returns a set of ValidateError, in conformance with
-// the JSON Typedef specification.
+// validating, or ErrUnproductiveRefCycle if refs would be followed forever.
+// Otherwise, returns a set of ValidateError, in conformance with the JSON
+// Typedef specification.
 func Validate(schema Schema, instance interface{}, opts ...ValidateOption) ([]ValidateError, error) {
-	settings := ValidateSettings{}
-	for _, opt := range opts {
-		opt(&settings)
-	}
+	settings := applyValidateOptions(opts)
 
 	return ValidateWithSettings(settings, schema, instance)
 }
 
+// IsValid returns whether instance is valid against schema. It stops at the
+// first error it finds, so it is faster than checking whether Validate returns
+// any errors.
+//
+// IsValid returns the same errors as Validate. The MaxErrors setting has no
+// effect.
+func IsValid(schema Schema, instance interface{}, opts ...ValidateOption) (bool, error) {
+	state := getValidateState(applyValidateOptions(opts))
+	defer putValidateState(state)
+
+	state.Root = schema
+	state.StopAtFirst = true
+	if err := validate(state, schema, instance, ""); err != nil && err != errMaxErrorsReached {
+		return false, err
+	}
+
+	return !state.Invalid, nil
+}
+
+// ValidateDefinition validates an instance against the definition of root with
+// the given name, as if root were a schema of the ref form referring to it.
+//
+// The SchemaPath of the returned errors start with "definitions" and name. Like
+// Validate, ValidateDefinition may return ErrMaxDepthExceeded or
+// ErrUnproductiveRefCycle, in which following the ref to the definition counts
+// as following a ref. It returns ErrNoSuchDefinition if root has no definition
+// with the given name.
+func ValidateDefinition(root Schema, name string, instance interface{}, opts ...ValidateOption) ([]ValidateError, error) {
+	if _, ok := root.Definitions[name]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoSuchDefinition, name)
+	}
+
+	state := getValidateState(applyValidateOptions(opts))
+	defer putValidateState(state)
+
+	state.Root = root
+	if err := validateRef(state, name, instance); err != nil && err != errMaxErrorsReached {
+		return nil, err
+	}
+
+	return state.errors(), nil
+}
+
 // ValidateWithSettings validates a schema against an instance, using a set of
 // settings.
 //
 // Returns ErrMaxDepthExceeded if too many refs are recursively followed while
-// validating. Otherwise, returns a set of ValidateError, in conformance with
-// the JSON Typedef specification.
+// validating, or ErrUnproductiveRefCycle if refs would be followed forever.
+// Otherwise, returns a set of ValidateError, in conformance with the JSON
+// Typedef specification.
 func ValidateWithSettings(settings ValidateSettings, schema Schema, instance interface{}) ([]ValidateError, error) {
-	state := validateState{
-		Errors:         []ValidateError{},
-		InstanceTokens: []string{},
-		SchemaTokens:   [][]string{[]string{}},
-		Root:           schema,
-		Settings:       settings,
-	}
+	state := getValidateState(settings)
+	defer putValidateState(state)
 
 	// errMaxErrorsReached is just an internal error used to quickly abort further
 	// validation. It is not an actual error for the end user, just a
 	// circuit-breaker used by validate internally.
-	if err := validate(&state, schema, instance, nil); err != nil && err != errMaxErrorsReached {
+	state.Root = schema
+	if err := validate(state, schema, instance, ""); err != nil && err != errMaxErrorsReached {
 		return nil, err
 	}
 
-	return state.Errors, nil
+	return state.errors(), nil
 }
 
-func validate(state *validateState, schema Schema, instance interface{}, parentTag *string) error {
+// validate validates instance against schema, a subschema of state.Root.
+// parentTag is the discriminator of the schema that schema is a mapping of, if
+// any.
+//
+// validate works on schemas as they are, so that validating against a schema
+// once is cheap. CompiledSchema has its own version of validate that works on
+// compiled nodes instead.
+func validate(state *validateState, schema Schema, instance interface{}, parentTag string) error {
+	state.NodesVisited++
+
 	if schema.Nullable && instance == nil {
 		return nil
 	}
@@ -102,103 +177,17 @@ func validate(state *validateState, schema Schema, instance interface{}, parentT
 	case FormEmpty:
 		return nil
 	case FormRef:
-		if len(state.SchemaTokens) == state.Settings.MaxDepth {
-			return ErrMaxDepthExceeded
-		}
-
-		state.SchemaTokens = append(state.SchemaTokens, []string{"definitions", *schema.Ref})
-		if err := validate(state, state.Root.Definitions[*schema.Ref], instance, nil); err != nil {
-			return err
-		}
-		state.SchemaTokens = state.SchemaTokens[:len(state.SchemaTokens)-1]
+		return validateRef(state, *schema.Ref, instance)
 	case FormType:
-		state.pushSchemaToken("type")
-
-		switch schema.Type {
-		case TypeBoolean:
-			if _, ok := instance.(bool); !ok {
-				if err := state.pushError(); err != nil {
-					return err
-				}
-			}
-		case TypeFloat32, TypeFloat64:
-			if _, ok := instance.(float64); !ok {
-				if err := state.pushError(); err != nil {
-					return err
-				}
-			}
-		case TypeInt8:
-			if err := validateInt(state, instance, -128.0, 127.0); err != nil {
-				return err
-			}
-		case TypeUint8:
-			if err := validateInt(state, instance, 0.0, 255.0); err != nil {
-				return err
-			}
-		case TypeInt16:
-			if err := validateInt(state, instance, -32768.0, 32767.0); err != nil {
-				return err
-			}
-		case TypeUint16:
-			if err := validateInt(state, instance, 0.0, 65535.0); err != nil {
-				return err
-			}
-		case TypeInt32:
-			if err := validateInt(state, instance, -2147483648.0, 2147483647.0); err != nil {
-				return err
-			}
-		case TypeUint32:
-			if err := validateInt(state, instance, 0.0, 4294967295.0); err != nil {
-				return err
-			}
-		case TypeString:
-			if _, ok := instance.(string); !ok {
-				if err := state.pushError(); err != nil {
-					return err
-				}
-			}
-		case TypeTimestamp:
-			if s, ok := instance.(string); ok {
-				if _, err := time.Parse(time.RFC3339, s); err != nil {
-					if err := state.pushError(); err != nil {
-						return err
-					}
-				}
-			} else {
-				if err := state.pushError(); err != nil {
-					return err
-				}
-			}
-		}
-
-		state.popSchemaToken()
+		return validateType(state, schema.Type, instance)
 	case FormEnum:
-		state.pushSchemaToken("enum")
-		if s, ok := instance.(string); ok {
-			ok := false
-			for _, value := range schema.Enum {
-				if s == value {
-					ok = true
-				}
-			}
-
-			if !ok {
-				if err := state.pushError(); err != nil {
-					return err
-				}
-			}
-		} else {
-			if err := state.pushError(); err != nil {
-				return err
-			}
-		}
-		state.popSchemaToken()
+		return validateEnum(state, schema.Enum, instance)
 	case FormElements:
 		state.pushSchemaToken("elements")
 		if arr, ok := instance.([]interface{}); ok {
 			for i, subInstance := range arr {
-				state.pushInstanceToken(strconv.Itoa(i))
-				if err := validate(state, *schema.Elements, subInstance, nil); err != nil {
+				state.pushInstanceIndex(i)
+				if err := validate(state, *schema.Elements, subInstance, ""); err != nil {
 					return err
 				}
 				state.popInstanceToken()
@@ -216,7 +205,7 @@ func validate(state *validateState, schema Schema, instance interface{}, parentT
 				state.pushSchemaToken(key)
 				if subInstance, ok := obj[key]; ok {
 					state.pushInstanceToken(key)
-					if err := validate(state, subSchema, subInstance, nil); err != nil {
+					if err := validate(state, subSchema, subInstance, ""); err != nil {
 						return err
 					}
 					state.popInstanceToken()
@@ -234,7 +223,7 @@ func validate(state *validateState, schema Schema, instance interface{}, parentT
 				state.pushSchemaToken(key)
 				if subInstance, ok := obj[key]; ok {
 					state.pushInstanceToken(key)
-					if err := validate(state, subSchema, subInstance, nil); err != nil {
+					if err := validate(state, subSchema, subInstance, ""); err != nil {
 						return err
 					}
 					state.popInstanceToken()
@@ -245,20 +234,12 @@ func validate(state *validateState, schema Schema, instance interface{}, parentT
 
 			if !schema.AdditionalProperties {
 				for key := range obj {
-					if parentTag != nil && key == *parentTag {
+					if parentTag != "" && key == parentTag {
 						continue
 					}
 
-					requiredOk := false
-					optionalOk := false
-
-					if schema.Properties != nil {
-						_, requiredOk = schema.Properties[key]
-					}
-
-					if schema.OptionalProperties != nil {
-						_, optionalOk = schema.OptionalProperties[key]
-					}
+					_, requiredOk := schema.Properties[key]
+					_, optionalOk := schema.OptionalProperties[key]
 
 					if !requiredOk && !optionalOk {
 						state.pushInstanceToken(key)
@@ -287,7 +268,7 @@ func validate(state *validateState, schema Schema, instance interface{}, parentT
 		if obj, ok := instance.(map[string]interface{}); ok {
 			for key, subInstance := range obj {
 				state.pushInstanceToken(key)
-				if err := validate(state, *schema.Values, subInstance, nil); err != nil {
+				if err := validate(state, *schema.Values, subInstance, ""); err != nil {
 					return err
 				}
 				state.popInstanceToken()
@@ -306,7 +287,7 @@ func validate(state *validateState, schema Schema, instance interface{}, parentT
 						state.pushSchemaToken("mapping")
 						state.pushSchemaToken(tagStr)
 
-						if err := validate(state, mapping, instance, &schema.Discriminator); err != nil {
+						if err := validate(state, mapping, instance, schema.Discriminator); err != nil {
 							return err
 						}
 
@@ -349,6 +330,119 @@ func validate(state *validateState, schema Schema, instance interface{}, parentT
 	return nil
 }
 
+// validateRef validates instance against the definition of state.Root with the
+// given name.
+func validateRef(state *validateState, name string, instance interface{}) error {
+	if len(state.SchemaBases) == state.Settings.MaxDepth {
+		return ErrMaxDepthExceeded
+	}
+
+	if state.Settings.MaxDepth == 0 && state.Refs.loops(name, len(state.InstanceTokens)) {
+		return ErrUnproductiveRefCycle
+	}
+
+	state.Refs = append(state.Refs, refFrame{Name: name, InstanceDepth: len(state.InstanceTokens)})
+	state.pushRef(name)
+	if err := validate(state, state.Root.Definitions[name], instance, ""); err != nil {
+		return err
+	}
+	state.popRef()
+	state.Refs = state.Refs[:len(state.Refs)-1]
+
+	return nil
+}
+
+// validateType validates instance against a schema of the type form.
+func validateType(state *validateState, typ Type, instance interface{}) error {
+	state.pushSchemaToken("type")
+
+	switch typ {
+	case TypeBoolean:
+		if _, ok := instance.(bool); !ok {
+			if err := state.pushError(); err != nil {
+				return err
+			}
+		}
+	case TypeFloat32, TypeFloat64:
+		if _, ok := instance.(float64); !ok {
+			if err := state.pushError(); err != nil {
+				return err
+			}
+		}
+	case TypeInt8:
+		if err := validateInt(state, instance, -128.0, 127.0); err != nil {
+			return err
+		}
+	case TypeUint8:
+		if err := validateInt(state, instance, 0.0, 255.0); err != nil {
+			return err
+		}
+	case TypeInt16:
+		if err := validateInt(state, instance, -32768.0, 32767.0); err != nil {
+			return err
+		}
+	case TypeUint16:
+		if err := validateInt(state, instance, 0.0, 65535.0); err != nil {
+			return err
+		}
+	case TypeInt32:
+		if err := validateInt(state, instance, -2147483648.0, 2147483647.0); err != nil {
+			return err
+		}
+	case TypeUint32:
+		if err := validateInt(state, instance, 0.0, 4294967295.0); err != nil {
+			return err
+		}
+	case TypeString:
+		if _, ok := instance.(string); !ok {
+			if err := state.pushError(); err != nil {
+				return err
+			}
+		}
+	case TypeTimestamp:
+		if s, ok := instance.(string); ok {
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				if err := state.pushError(); err != nil {
+					return err
+				}
+			}
+		} else {
+			if err := state.pushError(); err != nil {
+				return err
+			}
+		}
+	}
+
+	state.popSchemaToken()
+	return nil
+}
+
+// validateEnum validates instance against a schema of the enum form.
+func validateEnum(state *validateState, enum []string, instance interface{}) error {
+	state.pushSchemaToken("enum")
+	if s, ok := instance.(string); ok {
+		ok := false
+		for _, value := range enum {
+			if s == value {
+				ok = true
+			}
+		}
+
+		if !ok {
+			if err := state.pushError(); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := state.pushError(); err != nil {
+			return err
+		}
+	}
+	state.popSchemaToken()
+
+	return nil
+}
+
 func validateInt(state *validateState, instance interface{}, min, max float64) error {
 	if n, ok := instance.(float64); ok {
 		if i, f := math.Modf(n); f != 0.0 || i < min || i > max {
@@ -367,16 +461,116 @@ func validateInt(state *validateState, instance interface{}, min, max float64) e
 
 var errMaxErrorsReached = errors.New("jtd internal: max errors reached")
 
+// refFrame records a ref being followed, and how deep into the instance
+// validation was when it was followed.
+type refFrame struct {
+	Name          string
+	InstanceDepth int
+}
+
+// refStack is the stack of refs being followed.
+type refStack []refFrame
+
+// loops returns whether following the ref name, at the given instance depth,
+// would follow refs forever without consuming any input.
+func (rs refStack) loops(name string, instanceDepth int) bool {
+	// Refs followed since the instance was last descended into are the ones at
+	// the end of the stack with the current instance depth. If name is among
+	// them, following it again would loop forever.
+	for i := len(rs) - 1; i >= 0 && rs[i].InstanceDepth == instanceDepth; i-- {
+		if rs[i].Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateState is the state of an ongoing validation. To avoid allocating
+// while validating instances, paths are kept as stacks of tokens that get
+// reused between validations, and are only turned into a []string when an error
+// is recorded.
 type validateState struct {
 	Errors         []ValidateError
-	InstanceTokens []string
-	SchemaTokens   [][]string
-	Root           Schema
-	Settings       ValidateSettings
+	InstanceTokens []pathToken
+	SchemaTokens   []string
+
+	// SchemaBases holds, for each ref being followed, the index in SchemaTokens
+	// where the schema path of its definition starts. The first element is
+	// always zero, for the root.
+	SchemaBases []int
+
+	// Root is the root schema when validating against a Schema, and Refs are
+	// the refs being followed within it, to detect unproductive ref cycles.
+	// CompiledSchema finds those cycles ahead of time, and doesn't use either.
+	Root Schema
+	Refs refStack
+
+	// If StopAtFirst is true, the first error sets Invalid and stops
+	// validation, without being recorded in Errors.
+	StopAtFirst bool
+	Invalid     bool
+
+	// NodesVisited and MaxRefDepth are reported in ValidateResult.
+	NodesVisited int
+	MaxRefDepth  int
+
+	Settings ValidateSettings
+}
+
+// pathToken is a token of an instance path. Indices of elements are kept as
+// integers, and only formatted when an error is recorded.
+type pathToken struct {
+	Key   string
+	Index int // -1 for object keys
+}
+
+var validateStatePool = sync.Pool{
+	New: func() interface{} {
+		return &validateState{}
+	},
+}
+
+// getValidateState returns a validateState ready for a new validation. Return
+// it with putValidateState when done.
+func getValidateState(settings ValidateSettings) *validateState {
+	state := validateStatePool.Get().(*validateState)
+	state.Errors = nil
+	state.InstanceTokens = state.InstanceTokens[:0]
+	state.SchemaTokens = state.SchemaTokens[:0]
+	state.SchemaBases = append(state.SchemaBases[:0], 0)
+	state.Refs = state.Refs[:0]
+	state.StopAtFirst = false
+	state.Invalid = false
+	state.NodesVisited = 0
+	state.MaxRefDepth = 0
+	state.Settings = settings
+
+	return state
+}
+
+func putValidateState(state *validateState) {
+	state.Errors = nil
+	state.Root = Schema{}
+	validateStatePool.Put(state)
+}
+
+// errors returns the errors that were recorded, or an empty slice if there are
+// none.
+func (vs *validateState) errors() []ValidateError {
+	if vs.Errors == nil {
+		return []ValidateError{}
+	}
+
+	return vs.Errors
 }
 
 func (vs *validateState) pushInstanceToken(token string) {
-	vs.InstanceTokens = append(vs.InstanceTokens, token)
+	vs.InstanceTokens = append(vs.InstanceTokens, pathToken{Key: token, Index: -1})
+}
+
+func (vs *validateState) pushInstanceIndex(index int) {
+	vs.InstanceTokens = append(vs.InstanceTokens, pathToken{Index: index})
 }
 
 func (vs *validateState) popInstanceToken() {
@@ -384,24 +578,56 @@ func (vs *validateState) popInstanceToken() {
 }
 
 func (vs *validateState) pushSchemaToken(token string) {
-	vs.SchemaTokens[len(vs.SchemaTokens)-1] = append(vs.SchemaTokens[len(vs.SchemaTokens)-1], token)
+	vs.SchemaTokens = append(vs.SchemaTokens, token)
 }
 
 func (vs *validateState) popSchemaToken() {
-	last := vs.SchemaTokens[len(vs.SchemaTokens)-1]
-	vs.SchemaTokens[len(vs.SchemaTokens)-1] = last[:len(last)-1]
+	vs.SchemaTokens = vs.SchemaTokens[:len(vs.SchemaTokens)-1]
 }
 
-func (vs *validateState) pushError() error {
-	instanceTokens := make([]string, len(vs.InstanceTokens))
-	copy(instanceTokens, vs.InstanceTokens)
+// pushRef starts a new schema path, for the definition with the given name.
+func (vs *validateState) pushRef(name string) {
+	vs.SchemaBases = append(vs.SchemaBases, len(vs.SchemaTokens))
+	vs.SchemaTokens = append(vs.SchemaTokens, "definitions", name)
+
+	if len(vs.SchemaBases)-1 > vs.MaxRefDepth {
+		vs.MaxRefDepth = len(vs.SchemaBases) - 1
+	}
+}
 
-	schemaTokens := make([]string, len(vs.SchemaTokens[len(vs.SchemaTokens)-1]))
-	copy(schemaTokens, vs.SchemaTokens[len(vs.SchemaTokens)-1])
+func (vs *validateState) popRef() {
+	vs.SchemaTokens = vs.SchemaTokens[:vs.SchemaBases[len(vs.SchemaBases)-1]]
+	vs.SchemaBases = vs.SchemaBases[:len(vs.SchemaBases)-1]
+}
+
+// paths returns copies of the current instance and schema paths.
+func (vs *validateState) paths() ([]string, []string) {
+	instancePath := make([]string, len(vs.InstanceTokens))
+	for i, token := range vs.InstanceTokens {
+		if token.Index == -1 {
+			instancePath[i] = token.Key
+		} else {
+			instancePath[i] = strconv.Itoa(token.Index)
+		}
+	}
+
+	schemaTokens := vs.SchemaTokens[vs.SchemaBases[len(vs.SchemaBases)-1]:]
+	schemaPath := make([]string, len(schemaTokens))
+	copy(schemaPath, schemaTokens)
+
+	return instancePath, schemaPath
+}
+
+func (vs *validateState) pushError() error {
+	if vs.StopAtFirst {
+		vs.Invalid = true
+		return errMaxErrorsReached
+	}
 
+	instancePath, schemaPath := vs.paths()
 	vs.Errors = append(vs.Errors, ValidateError{
-		InstancePath: instanceTokens,
-		SchemaPath:   schemaTokens,
+		InstancePath: instancePath,
+		SchemaPath:   schemaPath,
 	})
 
 	if len(vs.Errors) == vs.Settings.MaxErrors {
@@ -2,6 +2,7 @@ package jtd_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"sort"
@@ -25,6 +26,38 @@ func TestMaxDepth(t *testing.T) {
 	assert.Equal(t, jtd.ErrMaxDepthExceeded, err)
 }
 
+func TestUnproductiveRefCycle(t *testing.T) {
+	a := "a"
+	b := "b"
+	tree := "tree"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"a":    jtd.Schema{Ref: &b},
+			"b":    jtd.Schema{Ref: &a, Nullable: true},
+			"tree": jtd.Schema{Elements: &jtd.Schema{Ref: &tree}},
+		},
+		Properties: map[string]jtd.Schema{
+			"loop": jtd.Schema{Ref: &a},
+			"tree": jtd.Schema{Ref: &tree},
+		},
+	}
+
+	// Productive recursion is fine, as is an unproductive cycle that is cut short
+	// by "nullable".
+	res, err := jtd.Validate(schema, map[string]interface{}{
+		"loop": nil,
+		"tree": []interface{}{[]interface{}{}, []interface{}{[]interface{}{}}},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+
+	_, err = jtd.Validate(schema, map[string]interface{}{
+		"loop": "foo",
+		"tree": []interface{}{},
+	})
+	assert.Equal(t, jtd.ErrUnproductiveRefCycle, err)
+}
+
 func TestMaxErrors(t *testing.T) {
 	schema := jtd.Schema{
 		Elements: &jtd.Schema{
@@ -39,6 +72,81 @@ func TestMaxErrors(t *testing.T) {
 	assert.Equal(t, 3, len(res))
 }
 
+func TestIsValid(t *testing.T) {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"a": jtd.Schema{Type: jtd.TypeString},
+			"b": jtd.Schema{Elements: &jtd.Schema{Type: jtd.TypeUint8}},
+		},
+	}
+
+	valid, err := jtd.IsValid(schema, map[string]interface{}{"a": "x", "b": []interface{}{1.0}})
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = jtd.IsValid(schema, map[string]interface{}{"a": "x", "b": []interface{}{256.0}})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	valid, err = jtd.IsValid(schema, nil, jtd.WithMaxErrors(5))
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	foo := "foo"
+	loop := jtd.Schema{
+		Definitions: map[string]jtd.Schema{"foo": jtd.Schema{Ref: &foo}},
+		Ref:         &foo,
+	}
+
+	_, err = jtd.IsValid(loop, nil, jtd.WithMaxDepth(3))
+	assert.Equal(t, jtd.ErrMaxDepthExceeded, err)
+
+	_, err = jtd.IsValid(loop, nil)
+	assert.Equal(t, jtd.ErrUnproductiveRefCycle, err)
+}
+
+func TestValidateDefinition(t *testing.T) {
+	var schema jtd.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"definitions": {
+			"order": {
+				"properties": {
+					"id": { "type": "string" },
+					"items": { "elements": { "ref": "item" } }
+				}
+			},
+			"item": { "properties": { "sku": { "type": "string" } } },
+			"loop": { "ref": "loop" }
+		}
+	}`), &schema))
+
+	errs, err := jtd.ValidateDefinition(schema, "order", map[string]interface{}{
+		"id":    "a",
+		"items": []interface{}{map[string]interface{}{"sku": 1.0}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []jtd.ValidateError{{
+		InstancePath: []string{"items", "0", "sku"},
+		SchemaPath:   []string{"definitions", "item", "properties", "sku", "type"},
+	}}, errs)
+
+	errs, err = jtd.ValidateDefinition(schema, "order", "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []jtd.ValidateError{{
+		InstancePath: []string{},
+		SchemaPath:   []string{"definitions", "order", "properties"},
+	}}, errs)
+
+	_, err = jtd.ValidateDefinition(schema, "order", "a", jtd.WithMaxDepth(1))
+	assert.Equal(t, jtd.ErrMaxDepthExceeded, err)
+
+	_, err = jtd.ValidateDefinition(schema, "loop", nil)
+	assert.Equal(t, jtd.ErrUnproductiveRefCycle, err)
+
+	_, err = jtd.ValidateDefinition(schema, "nonexistent", nil)
+	assert.True(t, errors.Is(err, jtd.ErrNoSuchDefinition))
+}
+
 type testCase struct {
 	Schema   jtd.Schema  `json:"schema"`
 	Instance interface{} `json:"instance"`
@@ -83,6 +191,10 @@ func TestValidation(t *testing.T) {
 			validateErrors, err := jtd.Validate(tt.Schema, tt.Instance)
 			assert.NoError(t, err)
 
+			valid, err := jtd.IsValid(tt.Schema, tt.Instance)
+			assert.NoError(t, err)
+			assert.Equal(t, len(expectedErrors) == 0, valid)
+
 			sort.Slice(validateErrors, func(i, j int) bool {
 				a0 := strings.Join(validateErrors[i].SchemaPath, "/")
 				b0 := strings.Join(validateErrors[j].SchemaPath, "/")
@@ -152,7 +264,7 @@ func ExampleValidate_maxDepth() {
 		Ref: &loop,
 	}
 
-	// If you ran this, you would overflow the stack:
+	// Without a max depth, this returns jtd.ErrUnproductiveRefCycle instead:
 	// jtd.Validate(schema, nil)
 
 	fmt.Println(jtd.Validate(schema, nil, jtd.WithMaxDepth(32)))
@@ -160,6 +272,24 @@ func ExampleValidate_maxDepth() {
 	// [] jtd: max depth exceeded
 }
 
+func ExampleValidate_unproductiveRefCycle() {
+	loop := "loop"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"loop": jtd.Schema{
+				Ref: &loop,
+			},
+		},
+		Ref: &loop,
+	}
+
+	fmt.Println(schema.RefCycles())
+	fmt.Println(jtd.Validate(schema, nil))
+	// Output:
+	// [[loop]]
+	// [] jtd: unproductive ref cycle
+}
+
 func ExampleValidate_maxErrors() {
 	schema := jtd.Schema{
 		Elements: &jtd.Schema{
@@ -175,3 +305,19 @@ func ExampleValidate_maxErrors() {
 	// [{[0] [elements type]} {[1] [elements type]} {[2] [elements type]} {[3] [elements type]} {[4] [elements type]}] <nil>
 	// [{[0] [elements type]} {[1] [elements type]} {[2] [elements type]}] <nil>
 }
+
+func ExampleValidateDefinition() {
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"user": jtd.Schema{
+				Properties: map[string]jtd.Schema{
+					"name": jtd.Schema{Type: jtd.TypeString},
+				},
+			},
+		},
+	}
+
+	fmt.Println(jtd.ValidateDefinition(schema, "user", map[string]interface{}{"name": 1.0}))
+	// Output:
+	// [{[name] [definitions user properties name type]}] <nil>
+}
@@ -0,0 +1,218 @@
+package jtd
+
+import (
+	"errors"
+	"sort"
+)
+
+// SkipSubtree can be returned from a WalkFunc to indicate that the children of
+// the schema passed to the WalkFunc should not be visited. It is never returned
+// as an error from Walk or Transform.
+var SkipSubtree = errors.New("jtd: skip subtree")
+
+// WalkFunc is the type of function called by Walk and Transform for each
+// schema they visit.
+//
+// path is the path to s from the root schema, using the same tokens as the
+// SchemaPath of ValidateError (e.g. ["properties", "foo", "elements"]). Each
+// call receives its own copy of path, so it is safe to retain.
+//
+// If a WalkFunc returns SkipSubtree, the children of s are not visited. If it
+// returns any other non-nil error, walking stops and that error is returned.
+type WalkFunc func(path []string, s *Schema) error
+
+// Walk calls fn for schema and each of its subschemas, in depth-first order.
+//
+// Parents are visited before their children. The children of a schema are
+// visited in this order: definitions, elements, properties, optionalProperties,
+// values, and then mapping. Within definitions, properties, optionalProperties,
+// and mapping, children are visited in order of their keys.
+//
+// Walk does not copy schema. fn is passed a copy of each Schema struct, so
+// changes to its fields are discarded, but its maps, slices, and pointers are
+// those of schema, and must not be modified. Use Transform to rewrite a schema.
+func Walk(schema Schema, fn WalkFunc) error {
+	return walk([]string{}, schema, fn)
+}
+
+func walk(path []string, s Schema, fn WalkFunc) error {
+	if err := fn(path, &s); err != nil {
+		if err == SkipSubtree {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := walkMap(path, "definitions", s.Definitions, fn); err != nil {
+		return err
+	}
+
+	if s.Elements != nil {
+		if err := walk(appendPath(path, "elements"), *s.Elements, fn); err != nil {
+			return err
+		}
+	}
+
+	if err := walkMap(path, "properties", s.Properties, fn); err != nil {
+		return err
+	}
+
+	if err := walkMap(path, "optionalProperties", s.OptionalProperties, fn); err != nil {
+		return err
+	}
+
+	if s.Values != nil {
+		if err := walk(appendPath(path, "values"), *s.Values, fn); err != nil {
+			return err
+		}
+	}
+
+	return walkMap(path, "mapping", s.Mapping, fn)
+}
+
+func walkMap(path []string, keyword string, schemas map[string]Schema, fn WalkFunc) error {
+	for _, key := range sortedKeys(schemas) {
+		if err := walk(appendPath(path, keyword, key), schemas[key], fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Transform is like Walk, except that changes fn makes to the schemas passed to
+// it are kept, and the rewritten schema is returned.
+//
+// Because parents are visited before their children, it is the rewritten
+// children of a schema that get visited. Transform does not modify schema
+// itself: before fn is called for a schema, its maps, slices, and pointers are
+// copied, so fn may modify them in place. The values in Metadata are not
+// copied, though.
+func Transform(schema Schema, fn WalkFunc) (Schema, error) {
+	if err := transform([]string{}, &schema, fn); err != nil {
+		return Schema{}, err
+	}
+
+	return schema, nil
+}
+
+func transform(path []string, s *Schema, fn WalkFunc) error {
+	copyShallow(s)
+	if err := fn(path, s); err != nil {
+		if err == SkipSubtree {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := transformMap(path, "definitions", s.Definitions, fn); err != nil {
+		return err
+	}
+
+	if s.Elements != nil {
+		if err := transform(appendPath(path, "elements"), s.Elements, fn); err != nil {
+			return err
+		}
+	}
+
+	if err := transformMap(path, "properties", s.Properties, fn); err != nil {
+		return err
+	}
+
+	if err := transformMap(path, "optionalProperties", s.OptionalProperties, fn); err != nil {
+		return err
+	}
+
+	if s.Values != nil {
+		if err := transform(appendPath(path, "values"), s.Values, fn); err != nil {
+			return err
+		}
+	}
+
+	return transformMap(path, "mapping", s.Mapping, fn)
+}
+
+// transformMap transforms each of schemas in place. schemas must have been
+// copied by copyShallow.
+func transformMap(path []string, keyword string, schemas map[string]Schema, fn WalkFunc) error {
+	for _, key := range sortedKeys(schemas) {
+		s := schemas[key]
+		if err := transform(appendPath(path, keyword, key), &s, fn); err != nil {
+			return err
+		}
+
+		schemas[key] = s
+	}
+
+	return nil
+}
+
+// copyShallow replaces the maps, slices, and pointers of s with copies, so that
+// changing them doesn't change the schema s was copied from.
+func copyShallow(s *Schema) {
+	if s.Metadata != nil {
+		metadata := make(map[string]interface{}, len(s.Metadata))
+		for k, v := range s.Metadata {
+			metadata[k] = v
+		}
+
+		s.Metadata = metadata
+	}
+
+	if s.Ref != nil {
+		ref := *s.Ref
+		s.Ref = &ref
+	}
+
+	if s.Enum != nil {
+		s.Enum = append(make([]string, 0, len(s.Enum)), s.Enum...)
+	}
+
+	if s.Elements != nil {
+		elements := *s.Elements
+		s.Elements = &elements
+	}
+
+	if s.Values != nil {
+		values := *s.Values
+		s.Values = &values
+	}
+
+	s.Definitions = copySchemas(s.Definitions)
+	s.Properties = copySchemas(s.Properties)
+	s.OptionalProperties = copySchemas(s.OptionalProperties)
+	s.Mapping = copySchemas(s.Mapping)
+}
+
+func copySchemas(schemas map[string]Schema) map[string]Schema {
+	if schemas == nil {
+		return nil
+	}
+
+	out := make(map[string]Schema, len(schemas))
+	for k, v := range schemas {
+		out[k] = v
+	}
+
+	return out
+}
+
+// appendPath returns a new path consisting of path followed by tokens. The
+// returned slice never shares memory with path.
+func appendPath(path []string, tokens ...string) []string {
+	out := make([]string, 0, len(path)+len(tokens))
+	out = append(out, path...)
+	return append(out, tokens...)
+}
+
+func sortedKeys(schemas map[string]Schema) []string {
+	keys := make([]string, 0, len(schemas))
+	for key := range schemas {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,183 @@
+package jtd_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	jtd "github.com/jsontypedef/json-typedef-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkOrder(t *testing.T) {
+	foo := "foo"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{
+			"foo": jtd.Schema{Type: jtd.TypeString},
+		},
+		Properties: map[string]jtd.Schema{
+			"b": jtd.Schema{Elements: &jtd.Schema{Ref: &foo}},
+			"a": jtd.Schema{Values: &jtd.Schema{}},
+		},
+		OptionalProperties: map[string]jtd.Schema{
+			"c": jtd.Schema{
+				Discriminator: "type",
+				Mapping: map[string]jtd.Schema{
+					"x": jtd.Schema{Properties: map[string]jtd.Schema{}},
+				},
+			},
+		},
+	}
+
+	var paths []string
+	err := jtd.Walk(schema, func(path []string, s *jtd.Schema) error {
+		paths = append(paths, "/"+strings.Join(path, "/"))
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/",
+		"/definitions/foo",
+		"/properties/a",
+		"/properties/a/values",
+		"/properties/b",
+		"/properties/b/elements",
+		"/optionalProperties/c",
+		"/optionalProperties/c/mapping/x",
+	}, paths)
+}
+
+func TestWalkSkipSubtree(t *testing.T) {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"a": jtd.Schema{Elements: &jtd.Schema{}},
+			"b": jtd.Schema{Elements: &jtd.Schema{}},
+		},
+	}
+
+	var paths []string
+	err := jtd.Walk(schema, func(path []string, s *jtd.Schema) error {
+		paths = append(paths, strings.Join(path, "/"))
+		if len(path) == 2 && path[1] == "a" {
+			return jtd.SkipSubtree
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "properties/a", "properties/b", "properties/b/elements"}, paths)
+}
+
+func TestWalkError(t *testing.T) {
+	errStop := errors.New("stop")
+	schema := jtd.Schema{Elements: &jtd.Schema{Elements: &jtd.Schema{}}}
+
+	visited := 0
+	err := jtd.Walk(schema, func(path []string, s *jtd.Schema) error {
+		visited++
+		if len(path) == 1 {
+			return errStop
+		}
+
+		return nil
+	})
+
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, 2, visited)
+}
+
+func TestTransform(t *testing.T) {
+	schema := jtd.Schema{
+		Properties: map[string]jtd.Schema{
+			"a": jtd.Schema{Type: jtd.TypeFloat32},
+		},
+	}
+
+	out, err := jtd.Transform(schema, func(path []string, s *jtd.Schema) error {
+		if s.Type == jtd.TypeFloat32 {
+			s.Type = jtd.TypeFloat64
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, jtd.Type(jtd.TypeFloat64), out.Properties["a"].Type)
+
+	// The input schema must not have been modified.
+	assert.Equal(t, jtd.Type(jtd.TypeFloat32), schema.Properties["a"].Type)
+}
+
+func TestTransformInPlace(t *testing.T) {
+	ref := "a"
+	schema := jtd.Schema{
+		Definitions: map[string]jtd.Schema{"a": jtd.Schema{Enum: []string{"x"}}},
+		Metadata:    map[string]interface{}{"description": "root"},
+		Properties: map[string]jtd.Schema{
+			"a": jtd.Schema{Ref: &ref},
+		},
+		Values: &jtd.Schema{Type: jtd.TypeString},
+	}
+
+	_, err := jtd.Transform(schema, func(path []string, s *jtd.Schema) error {
+		if s.Metadata != nil {
+			s.Metadata["description"] = "changed"
+		}
+
+		if s.Properties != nil {
+			s.Properties["b"] = jtd.Schema{}
+		}
+
+		if s.Ref != nil {
+			*s.Ref = "b"
+		}
+
+		if s.Enum != nil {
+			s.Enum[0] = "y"
+		}
+
+		if s.Values != nil {
+			s.Values.Type = jtd.TypeInt8
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, jtd.Schema{
+		Definitions: map[string]jtd.Schema{"a": jtd.Schema{Enum: []string{"x"}}},
+		Metadata:    map[string]interface{}{"description": "root"},
+		Properties: map[string]jtd.Schema{
+			"a": jtd.Schema{Ref: &ref},
+		},
+		Values: &jtd.Schema{Type: jtd.TypeString},
+	}, schema)
+	assert.Equal(t, "a", ref)
+}
+
+func ExampleTransform() {
+	schema := jtd.Schema{
+		Elements: &jtd.Schema{
+			Type:     jtd.TypeString,
+			Nullable: true,
+		},
+	}
+
+	// Make every schema in the tree non-nullable.
+	out, _ := jtd.Transform(schema, func(path []string, s *jtd.Schema) error {
+		if s.Nullable {
+			fmt.Println("rewriting", path)
+			s.Nullable = false
+		}
+
+		return nil
+	})
+
+	fmt.Println(out.Elements.Nullable)
+	// Output:
+	// rewriting [elements]
+	// false
+}